@@ -0,0 +1,35 @@
+package api
+
+import (
+	"github.com/lgtmco/lgtm/cache"
+	"github.com/lgtmco/lgtm/router/middleware/session"
+	"github.com/lgtmco/lgtm/store"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/gin-gonic/gin"
+)
+
+// GetConfig gets the effective .lgtm configuration of the repository,
+// merged over the organization-wide default configuration. The ref query
+// parameter selects the git reference, which defaults to the default branch.
+func GetConfig(c *gin.Context) {
+	var (
+		owner = c.Param("owner")
+		name  = c.Param("repo")
+		ref   = c.Query("ref")
+		user  = session.User(c)
+	)
+	repo, err := store.GetRepoOwnerName(c, user.Remote, owner, name)
+	if err != nil {
+		log.Errorf("Error getting repository %s. %s", name, err)
+		c.AbortWithStatus(404)
+		return
+	}
+	config, err := cache.GetConfig(c, user, repo, ref)
+	if err != nil {
+		log.Errorf("Error parsing .lgtm file for %s. %s", repo.Slug, err)
+		c.String(500, "Error parsing .lgtm file. %s.", err)
+		return
+	}
+	c.JSON(200, config)
+}
@@ -18,13 +18,13 @@ func GetMaintainer(c *gin.Context) {
 		name  = c.Param("repo")
 		user  = session.User(c)
 	)
-	repo, err := store.GetRepoOwnerName(c, owner, name)
+	repo, err := store.GetRepoOwnerName(c, user.Remote, owner, name)
 	if err != nil {
 		log.Errorf("Error getting repository %s. %s", name, err)
 		c.AbortWithStatus(404)
 		return
 	}
-	file, err := remote.GetContents(c, user, repo, "MAINTAINERS")
+	path, file, err := remote.FindContents(c, user, repo, model.MaintainerPaths(), "")
 	if err != nil {
 		log.Debugf("no MAINTAINERS file for %s. Checking for team members.", repo.Slug)
 		members, merr := cache.GetMembers(c, user, repo.Owner)
@@ -42,12 +42,13 @@ func GetMaintainer(c *gin.Context) {
 		}
 	}
 
-	maintainer, err := model.ParseMaintainer(file)
+	maintainer, err := model.ParseMaintainerFile(path, file)
 	if err != nil {
 		log.Errorf("Error parsing MAINTAINERS file for %s. %s", repo.Slug, err)
 		c.String(500, "Error parsing MAINTAINERS file. %s.", err)
 		return
 	}
+	maintainer.Path = path
 	c.JSON(200, maintainer)
 }
 
@@ -60,19 +61,19 @@ func GetMaintainerOrg(c *gin.Context) {
 		team  = c.Param("org")
 		user  = session.User(c)
 	)
-	repo, err := store.GetRepoOwnerName(c, owner, name)
+	repo, err := store.GetRepoOwnerName(c, user.Remote, owner, name)
 	if err != nil {
 		log.Errorf("Error getting repository %s. %s", name, err)
 		c.AbortWithStatus(404)
 		return
 	}
-	file, err := remote.GetContents(c, user, repo, "MAINTAINERS")
+	path, file, err := remote.FindContents(c, user, repo, model.MaintainerPaths(), "")
 	if err != nil {
 		log.Errorf("Error getting repository %s. %s", repo.Slug, err)
 		c.String(404, "MAINTAINERS file not found. %s", err)
 		return
 	}
-	maintainer, err := model.ParseMaintainer(file)
+	maintainer, err := model.ParseMaintainerFile(path, file)
 	if err != nil {
 		log.Errorf("Error parsing MAINTAINERS file for %s. %s", repo.Slug, err)
 		c.String(500, "Error parsing MAINTAINERS file. %s.", err)
@@ -84,5 +85,6 @@ func GetMaintainerOrg(c *gin.Context) {
 		c.String(500, "Error getting subset of MAINTAINERS file. %s.", err)
 		return
 	}
+	subset.Path = path
 	c.JSON(200, subset)
 }
@@ -30,7 +30,7 @@ func GetRepos(c *gin.Context) {
 	repoc := make([]*model.Repo, len(repos))
 	copy(repoc, repos)
 
-	repom, err := store.GetRepoIntersectMap(c, repos)
+	repom, err := store.GetRepoIntersectMap(c, user.Remote, repos)
 	if err != nil {
 		logrus.Errorf("Error getting active repository list. %s", err)
 		c.String(500, "Error getting active repository list")
@@ -53,8 +53,9 @@ func GetRepo(c *gin.Context) {
 	var (
 		owner = c.Param("owner")
 		name  = c.Param("repo")
+		user  = session.User(c)
 	)
-	repo, err := store.GetRepoOwnerName(c, owner, name)
+	repo, err := store.GetRepoOwnerName(c, user.Remote, owner, name)
 	if err != nil {
 		logrus.Errorf("Error getting repository %s. %s", name, err)
 		c.String(404, "Error getting repository %s", name)
@@ -71,10 +72,15 @@ func PostRepo(c *gin.Context) {
 		user  = session.User(c)
 	)
 
-	// verify repo doesn't already exist
-	if _, err := store.GetRepoOwnerName(c, owner, name); err == nil {
-		c.AbortWithStatus(409)
-		c.String(409, "Error activating a repository that is already active.")
+	// verify repo doesn't already exist, unless it was activated before
+	// hook payloads were signed, in which case the hook is signed again.
+	if repo, err := store.GetRepoOwnerName(c, user.Remote, owner, name); err == nil {
+		if repo.Signed {
+			c.AbortWithStatus(409)
+			c.String(409, "Error activating a repository that is already active.")
+			return
+		}
+		signHook(c, user, repo)
 		return
 	}
 
@@ -85,9 +91,11 @@ func PostRepo(c *gin.Context) {
 	}
 	repo.UserID = user.ID
 	repo.Secret = model.Rand()
+	repo.Remote = user.Remote
+	repo.Signed = true
 
 	// creates a token to authorize the link callback url
-	t := token.New(token.HookToken, repo.Slug)
+	t := token.New(token.HookToken, model.JoinRemote(repo.Remote, repo.Slug))
 	sig, err := t.Sign(repo.Secret)
 	if err != nil {
 		c.String(500, "Error activating repository. %s")
@@ -111,6 +119,48 @@ func PostRepo(c *gin.Context) {
 		c.String(500, "Error activating the repository. %s", err)
 		return
 	}
+
+	// protect the branches with an approval policy in the .lgtm file,
+	// in addition to the default branch protected with the hook. The
+	// repository is already active, and the branches are protected
+	// again when a hook is processed, so errors are only logged.
+	config, err := cache.GetConfig(c, user, repo, "")
+	if err != nil {
+		logrus.Warnf("Error parsing .lgtm file for %s. %s", repo.Slug, err)
+	} else if len(config.Branches) != 0 {
+		err = remote.SetBranches(c, user, repo, config.BranchPatterns())
+		if err != nil {
+			logrus.Warnf("Error protecting branches for %s. %s", repo.Slug, err)
+		}
+	}
+	c.JSON(200, repo)
+}
+
+// signHook is a helper function that creates the webhook of a repository
+// activated before hook payloads were signed again, with the repository
+// secret, since unsigned hook deliveries are rejected.
+func signHook(c *gin.Context, user *model.User, repo *model.Repo) {
+	sig, err := token.New(token.HookToken, model.JoinRemote(repo.Remote, repo.Slug)).Sign(repo.Secret)
+	if err != nil {
+		c.String(500, "Error activating repository. %s", err)
+		return
+	}
+	link := fmt.Sprintf(
+		"%s/hook?access_token=%s",
+		httputil.GetURL(c.Request),
+		sig,
+	)
+	err = remote.SetHook(c, user, repo, link)
+	if err != nil {
+		c.String(500, "Error creating hook. %s", err)
+		return
+	}
+	repo.Signed = true
+	err = store.UpdateRepo(c, repo)
+	if err != nil {
+		c.String(500, "Error activating the repository. %s", err)
+		return
+	}
 	c.JSON(200, repo)
 }
 
@@ -121,7 +171,7 @@ func DeleteRepo(c *gin.Context) {
 		name  = c.Param("repo")
 		user  = session.User(c)
 	)
-	repo, err := store.GetRepoOwnerName(c, owner, name)
+	repo, err := store.GetRepoOwnerName(c, user.Remote, owner, name)
 	if err != nil {
 		logrus.Errorf("Error getting repository %s. %s", name, err)
 		c.AbortWithStatus(404)
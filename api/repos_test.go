@@ -0,0 +1,73 @@
+package api
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lgtmco/lgtm/model"
+
+	remote "github.com/lgtmco/lgtm/remote/mock"
+	store "github.com/lgtmco/lgtm/store/mock"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/franela/goblin"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRepos(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logrus.SetOutput(ioutil.Discard)
+
+	g := goblin.Goblin(t)
+
+	g.Describe("Repo endpoint", func() {
+
+		var (
+			s *store.Store
+			r *remote.Remote
+			e *gin.Engine
+		)
+
+		g.BeforeEach(func() {
+			s = new(store.Store)
+			r = new(remote.Remote)
+			e = gin.New()
+			e.Use(func(c *gin.Context) {
+				c.Set("user", fakeUser)
+				c.Set("store", s)
+				c.Set("remote", r)
+			})
+			e.POST("/api/repos/:owner/:repo", PostRepo)
+		})
+
+		g.It("Should reject a repository that is already active", func() {
+			repo := &model.Repo{Owner: "octocat", Name: "hello-world", Slug: "octocat/hello-world", Signed: true}
+			s.On("GetRepoSlug", "", repo.Slug).Return(repo, nil)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/api/repos/octocat/hello-world", nil)
+			e.ServeHTTP(w, req)
+
+			g.Assert(w.Code).Equal(409)
+			r.AssertNotCalled(t, "SetHook", mock.Anything, mock.Anything, mock.Anything)
+		})
+
+		g.It("Should sign the hook of a repository activated before signing", func() {
+			repo := &model.Repo{Owner: "octocat", Name: "hello-world", Slug: "octocat/hello-world", Secret: "b9015b08"}
+			s.On("GetRepoSlug", "", repo.Slug).Return(repo, nil)
+			s.On("UpdateRepo", repo).Return(nil)
+			r.On("SetHook", fakeUser, repo, mock.Anything).Return(nil)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/api/repos/octocat/hello-world", nil)
+			e.ServeHTTP(w, req)
+
+			g.Assert(w.Code).Equal(200)
+			g.Assert(repo.Signed).IsTrue()
+			s.AssertCalled(t, "UpdateRepo", repo)
+		})
+	})
+}
@@ -0,0 +1,116 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/lgtmco/lgtm/model"
+	"github.com/lgtmco/lgtm/remote"
+	"github.com/lgtmco/lgtm/router/middleware/session"
+	"github.com/lgtmco/lgtm/store"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/gin-gonic/gin"
+)
+
+// validateRequest holds the contents of the files to validate. A file
+// that is omitted is fetched from the repository, searching the paths
+// configured on the server. The path of a posted file selects its format.
+type validateRequest struct {
+	Config          string `json:"config"`
+	ConfigPath      string `json:"config_path"`
+	Maintainers     string `json:"maintainers"`
+	MaintainersPath string `json:"maintainers_path"`
+}
+
+// PostValidate validates the .lgtm and MAINTAINERS files posted in the
+// request body, or fetched from the git reference in the ref query
+// parameter, which defaults to the default branch.
+func PostValidate(c *gin.Context) {
+	var (
+		owner = c.Param("owner")
+		name  = c.Param("repo")
+		ref   = c.Query("ref")
+		user  = session.User(c)
+	)
+	repo, err := store.GetRepoOwnerName(c, user.Remote, owner, name)
+	if err != nil {
+		log.Errorf("Error getting repository %s. %s", name, err)
+		c.AbortWithStatus(404)
+		return
+	}
+	in := new(validateRequest)
+	err = json.NewDecoder(c.Request.Body).Decode(in)
+	if err != nil && err != io.EOF {
+		c.String(400, "Error parsing request body. %s", err)
+		return
+	}
+
+	rcpath, rcfile := in.ConfigPath, []byte(in.Config)
+	if len(rcfile) == 0 {
+		rcpath, rcfile, _ = remote.FindContents(c, user, repo, model.ConfigPaths(), ref)
+	}
+	if len(rcpath) == 0 {
+		rcpath = ".lgtm"
+	}
+	config, problems := model.ValidateConfigFile(rcpath, rcfile)
+	if config != nil {
+		config.Path = rcpath
+	}
+
+	path, file := in.MaintainersPath, []byte(in.Maintainers)
+	if len(file) == 0 {
+		path, file, _ = remote.FindContents(c, user, repo, model.MaintainerPaths(), ref)
+	}
+	if len(path) == 0 {
+		path = "MAINTAINERS"
+	}
+	// without a MAINTAINERS file the approvers are the members
+	// of the org maintainers team, and there is nothing to check.
+	if len(file) != 0 {
+		maintainer, errs := model.ValidateMaintainerFile(path, file)
+		problems = append(problems, errs...)
+		if maintainer != nil {
+			maintainer.Path = path
+			problems = append(problems, validateLogins(c, user, maintainer)...)
+		}
+		if maintainer != nil && config != nil && config.Source == model.SourceMaintainers {
+			problems = append(problems, model.ValidateOrgs(config, maintainer)...)
+		}
+	}
+
+	if problems == nil {
+		problems = []*model.Problem{}
+	}
+	c.JSON(200, gin.H{
+		"valid":            len(problems) == 0,
+		"problems":         problems,
+		"config_path":      rcpath,
+		"maintainers_path": path,
+	})
+}
+
+// validateLogins is a helper function that returns a problem for each
+// person in the MAINTAINERS file without an account on the remote system.
+func validateLogins(c *gin.Context, user *model.User, maintainer *model.Maintainer) []*model.Problem {
+	var logins []string
+	for _, person := range maintainer.People {
+		logins = append(logins, person.Login)
+	}
+	sort.Strings(logins)
+
+	var problems []*model.Problem
+	for _, login := range logins {
+		_, err := remote.GetMember(c, user, login)
+		if err != nil {
+			problems = append(problems, &model.Problem{
+				File:    maintainer.Path,
+				Kind:    model.ProblemLogin,
+				Message: fmt.Sprintf("Login %s not found. %s", login, err),
+			})
+		}
+	}
+	return problems
+}
@@ -0,0 +1,147 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lgtmco/lgtm/model"
+
+	remote "github.com/lgtmco/lgtm/remote/mock"
+	store "github.com/lgtmco/lgtm/store/mock"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/franela/goblin"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestValidate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logrus.SetOutput(ioutil.Discard)
+
+	g := goblin.Goblin(t)
+
+	g.Describe("Validate endpoint", func() {
+
+		var (
+			s    *store.Store
+			r    *remote.Remote
+			e    *gin.Engine
+			repo *model.Repo
+		)
+
+		// validate is a helper function that posts the body to the
+		// validate endpoint and returns the response.
+		validate := func(query, body string) (int, *validateResponse) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/api/repos/octocat/hello-world/validate"+query, bytes.NewBufferString(body))
+			e.ServeHTTP(w, req)
+			out := new(validateResponse)
+			json.Unmarshal(w.Body.Bytes(), out)
+			return w.Code, out
+		}
+
+		g.BeforeEach(func() {
+			s = new(store.Store)
+			r = new(remote.Remote)
+			e = gin.New()
+			e.Use(func(c *gin.Context) {
+				c.Set("user", fakeUser)
+				c.Set("store", s)
+				c.Set("remote", r)
+			})
+			e.POST("/api/repos/:owner/:repo/validate", PostValidate)
+
+			repo = &model.Repo{Owner: "octocat", Name: "hello-world", Slug: "octocat/hello-world"}
+			s.On("GetRepoSlug", "", repo.Slug).Return(repo, nil)
+			r.On("GetMember", fakeUser, mock.Anything).Return(&model.Member{}, nil)
+		})
+
+		g.It("Should validate the files at the git reference", func() {
+			r.On("GetContentsRef", fakeUser, repo, ".lgtm", "feature").Return([]byte("approvals = 2\n"), nil)
+			r.On("GetContentsRef", fakeUser, repo, "MAINTAINERS", "feature").Return([]byte(fakeMaintainers), nil)
+
+			code, out := validate("?ref=feature", "")
+			g.Assert(code).Equal(200)
+			g.Assert(out.Valid).IsTrue()
+			g.Assert(len(out.Problems)).Equal(0)
+			g.Assert(out.ConfigPath).Equal(".lgtm")
+			g.Assert(out.MaintainersPath).Equal("MAINTAINERS")
+		})
+
+		g.It("Should report an unknown key", func() {
+			r.On("GetContentsRef", fakeUser, repo, "MAINTAINERS", "").Return([]byte(fakeMaintainers), nil)
+
+			code, out := validate("", `{"config": "aprovals = 2\n"}`)
+			g.Assert(code).Equal(200)
+			g.Assert(out.Valid).IsFalse()
+			g.Assert(len(out.Problems)).Equal(1)
+			g.Assert(out.Problems[0].Kind).Equal(model.ProblemUnknownKey)
+			g.Assert(out.Problems[0].File).Equal(".lgtm")
+		})
+
+		g.It("Should report an invalid pattern", func() {
+			r.On("GetContentsRef", fakeUser, repo, "MAINTAINERS", "").Return([]byte(fakeMaintainers), nil)
+
+			code, out := validate("", `{"config": "pattern = \"(LGTM\"\n", "config_path": ".lgtm.toml"}`)
+			g.Assert(code).Equal(200)
+			g.Assert(out.Valid).IsFalse()
+			g.Assert(len(out.Problems)).Equal(1)
+			g.Assert(out.Problems[0].Kind).Equal(model.ProblemPattern)
+			g.Assert(out.Problems[0].File).Equal(".lgtm.toml")
+		})
+
+		g.It("Should report an org missing from the MAINTAINERS file", func() {
+			r.On("GetContentsRef", fakeUser, repo, ".lgtm", "").Return([]byte(fakeConfigOrg), nil)
+			r.On("GetContentsRef", fakeUser, repo, "MAINTAINERS", "").Return([]byte(fakeMaintainers), nil)
+
+			code, out := validate("", "")
+			g.Assert(code).Equal(200)
+			g.Assert(out.Valid).IsFalse()
+			g.Assert(len(out.Problems)).Equal(1)
+			g.Assert(out.Problems[0].Kind).Equal(model.ProblemOrg)
+			g.Assert(out.Problems[0].File).Equal(".lgtm")
+		})
+
+		g.It("Should return a 404 error for an inactive repository", func() {
+			s = new(store.Store)
+			s.On("GetRepoSlug", "", repo.Slug).Return(nil, fmt.Errorf("Not Found"))
+
+			code, _ := validate("", "")
+			g.Assert(code).Equal(404)
+		})
+	})
+}
+
+// validateResponse is the response of the validate endpoint.
+type validateResponse struct {
+	Valid           bool             `json:"valid"`
+	Problems        []*model.Problem `json:"problems"`
+	ConfigPath      string           `json:"config_path"`
+	MaintainersPath string           `json:"maintainers_path"`
+}
+
+var (
+	fakeMaintainers = `
+[people]
+
+	[people.bradrydzewski]
+	login = "bradrydzewski"
+
+[org]
+
+	[org.core]
+	people = ["bradrydzewski"]
+`
+	fakeConfigOrg = `
+[[rule]]
+name = "docs"
+paths = ["docs/**"]
+team = "docs"
+`
+)
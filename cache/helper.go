@@ -6,6 +6,7 @@ import (
 	"golang.org/x/net/context"
 
 	"github.com/lgtmco/lgtm/model"
+	"github.com/lgtmco/lgtm/policy"
 	"github.com/lgtmco/lgtm/remote"
 )
 
@@ -13,7 +14,7 @@ import (
 // associated with the current context.
 func GetRepos(c context.Context, user *model.User) ([]*model.Repo, error) {
 	key := fmt.Sprintf("repos:%s",
-		user.Login,
+		model.JoinRemote(user.Remote, user.Login),
 	)
 	// if we fetch from the cache we can return immediately
 	val, err := FromContext(c).Get(key)
@@ -34,7 +35,7 @@ func GetRepos(c context.Context, user *model.User) ([]*model.Repo, error) {
 // associated with the current context.
 func GetTeams(c context.Context, user *model.User) ([]*model.Team, error) {
 	key := fmt.Sprintf("teams:%s",
-		user.Login,
+		model.JoinRemote(user.Remote, user.Login),
 	)
 	// if we fetch from the cache we can return immediately
 	val, err := FromContext(c).Get(key)
@@ -55,7 +56,7 @@ func GetTeams(c context.Context, user *model.User) ([]*model.Team, error) {
 // associated with the current repository.
 func GetPerm(c context.Context, user *model.User, owner, name string) (*model.Perm, error) {
 	key := fmt.Sprintf("perms:%s:%s/%s",
-		user.Login,
+		model.JoinRemote(user.Remote, user.Login),
 		owner,
 		name,
 	)
@@ -77,7 +78,7 @@ func GetPerm(c context.Context, user *model.User, owner, name string) (*model.Pe
 // GetMembers returns the team members from the cache.
 func GetMembers(c context.Context, user *model.User, team string) ([]*model.Member, error) {
 	key := fmt.Sprintf("members:%s",
-		team,
+		model.JoinRemote(user.Remote, team),
 	)
 	// if we fetch from the cache we can return immediately
 	val, err := FromContext(c).Get(key)
@@ -93,3 +94,78 @@ func GetMembers(c context.Context, user *model.User, team string) ([]*model.Memb
 	FromContext(c).Set(key, members)
 	return members, nil
 }
+
+// GetAuthor returns the classification of the pull request author
+// in the repository from the cache.
+func GetAuthor(c context.Context, user *model.User, repo *model.Repo, login string) (*model.Author, error) {
+	key := fmt.Sprintf("author:%s/%s:%s",
+		model.JoinRemote(user.Remote, repo.Owner),
+		repo.Name,
+		login,
+	)
+	// if we fetch from the cache we can return immediately
+	val, err := FromContext(c).Get(key)
+	if err == nil {
+		return val.(*model.Author), nil
+	}
+	// else we try to grab from the remote system and
+	// populate our cache.
+	author, err := remote.GetAuthor(c, user, repo, login)
+	if err != nil {
+		return nil, err
+	}
+	FromContext(c).Set(key, author)
+	return author, nil
+}
+
+// GetDefaultConfig returns the path and contents of the organization-wide
+// default .lgtm file of the owner from the cache, or nil if the owner has
+// no default file.
+func GetDefaultConfig(c context.Context, user *model.User, owner string) (string, []byte) {
+	repo := model.DefaultRepo(owner)
+	if repo == nil {
+		return "", nil
+	}
+	key := fmt.Sprintf("config:%s",
+		model.JoinRemote(user.Remote, repo.Slug),
+	)
+	// if we fetch from the cache we can return immediately
+	val, err := FromContext(c).Get(key)
+	if err == nil {
+		file := val.(*configFile)
+		return file.path, file.data
+	}
+	// else we try to grab from the remote system and
+	// populate our cache. A missing file is cached as well
+	// to avoid a remote call for every pull request.
+	path, data, err := remote.FindContents(c, user, repo, model.ConfigPaths(), "")
+	if err != nil {
+		path, data = "", []byte{}
+	}
+	FromContext(c).Set(key, &configFile{path, data})
+	return path, data
+}
+
+// configFile is a .lgtm file cached with the path it was found
+// at, which determines the format of the file.
+type configFile struct {
+	path string
+	data []byte
+}
+
+// GetConfig returns the .lgtm file of the repository at the git reference
+// merged over the organization-wide default .lgtm file of the repository
+// owner, and raised to the minimum policy enforced by the server. An empty
+// reference is the default branch. The .lgtm file is the first file found
+// in the search paths configured on the server.
+func GetConfig(c context.Context, user *model.User, repo *model.Repo, ref string) (*model.Config, error) {
+	defaultsPath, defaults := GetDefaultConfig(c, user, repo.Owner)
+	path, file, _ := remote.FindContents(c, user, repo, model.ConfigPaths(), ref)
+	config, err := model.ParseConfigDefault(defaultsPath, defaults, path, file)
+	if err != nil {
+		return nil, err
+	}
+	config.Path = path
+	config.Enforce(policy.FromContext(c), repo.Owner)
+	return config, nil
+}
@@ -134,6 +134,70 @@ func TestHelper(t *testing.T) {
 			g.Assert(p == nil).IsTrue()
 			g.Assert(err).Equal(fakeErr)
 		})
+
+		g.It("Should get the author from remote", func() {
+			r.On("GetAuthor", fakeUser, fakeRepo, "hubot").Return(fakeAuthor, nil).Once()
+			a, err := GetAuthor(c, fakeUser, fakeRepo, "hubot")
+			g.Assert(a).Equal(fakeAuthor)
+			g.Assert(err).Equal(nil)
+		})
+
+		g.It("Should get the author from cache", func() {
+			key := "author:octocat/Hello-World:hubot"
+
+			Set(c, key, fakeAuthor)
+			r.On("GetAuthor", fakeUser, fakeRepo, "hubot").Return(nil, fakeErr).Once()
+			a, err := GetAuthor(c, fakeUser, fakeRepo, "hubot")
+			g.Assert(a).Equal(fakeAuthor)
+			g.Assert(err).Equal(nil)
+		})
+
+		g.It("Should get the author error", func() {
+			r.On("GetAuthor", fakeUser, fakeRepo, "hubot").Return(nil, fakeErr).Once()
+			a, err := GetAuthor(c, fakeUser, fakeRepo, "hubot")
+			g.Assert(a == nil).IsTrue()
+			g.Assert(err).Equal(fakeErr)
+		})
+
+		g.It("Should merge the default config", func() {
+			defaults := []byte("approvals = 3\nself_approval_off = true\n")
+			r.On("GetContentsRef", fakeUser, model.DefaultRepo("octocat"), ".lgtm", "").Return(defaults, nil).Once()
+			r.On("GetContentsRef", fakeUser, fakeRepo, ".lgtm", "").Return([]byte("approvals = 1\n"), nil).Once()
+			config, err := GetConfig(c, fakeUser, fakeRepo, "")
+			g.Assert(err).Equal(nil)
+			g.Assert(config.Approvals).Equal(1)
+			g.Assert(config.SelfApprovalOff).IsTrue()
+			g.Assert(config.Path).Equal(".lgtm")
+		})
+
+		g.It("Should get the default config from cache", func() {
+			Set(c, "config:octocat/.github", &configFile{".lgtm", []byte("approvals = 3\n")})
+			for _, path := range model.ConfigPaths() {
+				r.On("GetContentsRef", fakeUser, fakeRepo, path, "").Return(nil, fakeErr).Once()
+			}
+			config, err := GetConfig(c, fakeUser, fakeRepo, "")
+			g.Assert(err).Equal(nil)
+			g.Assert(config.Approvals).Equal(3)
+			g.Assert(config.Path).Equal("")
+		})
+
+		g.It("Should search the config paths", func() {
+			Set(c, "config:octocat/.github", &configFile{"", []byte{}})
+			r.On("GetContentsRef", fakeUser, fakeRepo, ".lgtm", "master").Return(nil, fakeErr).Once()
+			r.On("GetContentsRef", fakeUser, fakeRepo, ".lgtm.yml", "master").Return([]byte("approvals: 1\n"), nil).Once()
+			config, err := GetConfig(c, fakeUser, fakeRepo, "master")
+			g.Assert(err).Equal(nil)
+			g.Assert(config.Approvals).Equal(1)
+			g.Assert(config.Path).Equal(".lgtm.yml")
+		})
+
+		g.It("Should parse the config in the format of its path", func() {
+			Set(c, "config:octocat/.github", &configFile{"", []byte{}})
+			r.On("GetContentsRef", fakeUser, fakeRepo, ".lgtm", "master").Return(nil, fakeErr).Once()
+			r.On("GetContentsRef", fakeUser, fakeRepo, ".lgtm.yml", "master").Return([]byte("approvals = 1\n"), nil).Once()
+			_, err := GetConfig(c, fakeUser, fakeRepo, "master")
+			g.Assert(err != nil).IsTrue()
+		})
 	})
 }
 
@@ -154,4 +218,5 @@ var (
 	fakeMembers = []*model.Member{
 		{Login: "octocat"},
 	}
+	fakeAuthor = &model.Author{Login: "hubot", Member: true}
 )
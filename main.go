@@ -36,6 +36,8 @@ func main() {
 		middleware.Store(),
 		middleware.Remote(),
 		middleware.Cache(),
+		middleware.Policy(),
+		middleware.Scheduler(),
 	)
 
 	if *cert != "" {
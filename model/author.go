@@ -0,0 +1,147 @@
+package model
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Author classes with an approval policy in the .lgtm file.
+const (
+	AuthorExternal  = "external"
+	AuthorFirstTime = "first_time"
+	AuthorBot       = "bot"
+)
+
+// Author represents the classification of a pull request author
+// by the remote system.
+type Author struct {
+	Login string `json:"login"`
+
+	// Member is true when the author owns the repository, or is a
+	// member of the organization owning the repository.
+	Member bool `json:"member"`
+
+	// FirstTime is true when the author has not contributed to
+	// the repository before.
+	FirstTime bool `json:"first_time"`
+}
+
+// AuthorPolicy represents the approval policy of pull requests by
+// a class of authors in the .lgtm file, for example:
+//
+//	[authors.external]
+//	approvals = 3
+//
+//	[authors.first_time]
+//	approvals = 3
+//	team = "core"
+//
+//	[authors.bot]
+//	approvals = 1
+//	logins = ["dependabot[bot]", "renovate[bot]"]
+//
+// The policy replaces the approvals and team of the default rule. A
+// pull request by an allowlisted bot only uses the bot policy, while
+// the policies of an external and first-time author both apply, with
+// the first-time team taking precedence.
+type AuthorPolicy struct {
+	Approvals int      `json:"approvals"        toml:"approvals" yaml:"approvals"`
+	Team      string   `json:"team,omitempty"   toml:"team"      yaml:"team"`
+	Logins    []string `json:"logins,omitempty" toml:"logins"    yaml:"logins"`
+}
+
+// IsBot returns true if the login is an allowlisted bot.
+func (c *Config) IsBot(login string) bool {
+	bot, ok := c.Authors[AuthorBot]
+	if !ok {
+		return false
+	}
+	for _, allowed := range bot.Logins {
+		if strings.EqualFold(allowed, login) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClassifiesAuthors returns true if the configuration has a policy for
+// external or first-time authors, which requires the classification of
+// the pull request author by the remote system.
+func (c *Config) ClassifiesAuthors() bool {
+	_, external := c.Authors[AuthorExternal]
+	_, first := c.Authors[AuthorFirstTime]
+	return external || first
+}
+
+// AuthorClasses returns the classes of the author with an approval
+// policy, in the order the policies apply.
+func (c *Config) AuthorClasses(author *Author) []string {
+	if c.IsBot(author.Login) {
+		return []string{AuthorBot}
+	}
+	var classes []string
+	if _, ok := c.Authors[AuthorExternal]; ok && !author.Member {
+		classes = append(classes, AuthorExternal)
+	}
+	if _, ok := c.Authors[AuthorFirstTime]; ok && author.FirstTime {
+		classes = append(classes, AuthorFirstTime)
+	}
+	return classes
+}
+
+// ForAuthor returns the configuration that applies to pull requests
+// by the author. An allowlisted bot may require fewer approvals, while
+// external and first-time authors require the most approvals of their
+// policies.
+func (c *Config) ForAuthor(author *Author) *Config {
+	classes := c.AuthorClasses(author)
+	if len(classes) == 0 {
+		return c
+	}
+	config := *c
+	for _, class := range classes {
+		policy := c.Authors[class]
+		switch {
+		case policy.Approvals == 0:
+		case class == AuthorBot:
+			config.Approvals = policy.Approvals
+		case policy.Approvals > config.Approvals:
+			config.Approvals = policy.Approvals
+		}
+		if len(policy.Team) != 0 {
+			config.team = policy.Team
+		}
+	}
+	return &config
+}
+
+// authorClasses is a helper function that returns the sorted
+// author classes with an approval policy.
+func (c *Config) authorClasses() []string {
+	var classes []string
+	for class := range c.Authors {
+		classes = append(classes, class)
+	}
+	sort.Strings(classes)
+	return classes
+}
+
+// validateAuthors is a helper function that validates the author
+// policies of the .lgtm file.
+func (c *Config) validateAuthors() error {
+	for _, class := range c.authorClasses() {
+		policy := c.Authors[class]
+		switch {
+		case class != AuthorExternal && class != AuthorFirstTime && class != AuthorBot:
+			return fmt.Errorf("Invalid author class %s", class)
+		case policy == nil:
+			return fmt.Errorf("Invalid policy for author class %s", class)
+		case policy.Approvals < 0:
+			return fmt.Errorf("Invalid approvals for author class %s. Approvals must not be negative.", class)
+		case class != AuthorBot && len(policy.Logins) != 0:
+			return fmt.Errorf("Invalid logins for author class %s. Logins are only allowed for bots.", class)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package model
+
+import "testing"
+
+func TestConfigAuthors(t *testing.T) {
+	config, err := ParseConfigStr(configAuthors)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	var tests = []struct {
+		author    *Author
+		approvals int
+		team      string
+	}{
+		{&Author{Login: "octocat", Member: true}, 2, ""},
+		{&Author{Login: "spaceghost"}, 3, ""},
+		{&Author{Login: "octocat", Member: true, FirstTime: true}, 2, "core"},
+		{&Author{Login: "spaceghost", FirstTime: true}, 3, "core"},
+		{&Author{Login: "Dependabot[bot]"}, 1, "deps"},
+	}
+	for _, test := range tests {
+		rules := config.ForAuthor(test.author).defaultRules()
+		if got := rules[0].Approvals; got != test.approvals {
+			t.Errorf("Wanted %d approvals for %v, got %d", test.approvals, test.author, got)
+		}
+		if got := rules[0].Team; got != test.team {
+			t.Errorf("Wanted team %q for %v, got %q", test.team, test.author, got)
+		}
+	}
+	if config.Approvals != 2 || config.team != "" {
+		t.Errorf("Wanted the configuration unchanged, got %d approvals", config.Approvals)
+	}
+
+	if !config.ClassifiesAuthors() {
+		t.Errorf("Wanted authors classified for external and first-time policies")
+	}
+	config, _ = ParseConfigStr("[authors.bot]\napprovals = 1\nlogins = [\"renovate[bot]\"]\n")
+	if config.ClassifiesAuthors() {
+		t.Errorf("Wanted authors not classified for a bot policy")
+	}
+	if !config.IsBot("renovate[bot]") || config.IsBot("octocat") {
+		t.Errorf("Wanted only allowlisted logins recognized as bots")
+	}
+}
+
+func TestConfigAuthorsInvalid(t *testing.T) {
+	var tests = []string{
+		"[authors.contractor]\napprovals = 3\n",
+		"[authors.external]\napprovals = -1\n",
+		"[authors.external]\nlogins = [\"spaceghost\"]\n",
+	}
+	for _, test := range tests {
+		if _, err := ParseConfigStr(test); err == nil {
+			t.Errorf("Wanted error for author policy %q", test)
+		}
+	}
+}
+
+func TestValidateAuthorOrgs(t *testing.T) {
+	config, _ := ParseConfigStr(configAuthors)
+	maintainer, _ := ParseMaintainerStr(maintainerFileOrg)
+	problems := ValidateOrgs(config, maintainer)
+	if got, want := len(problems), 1; got != want {
+		t.Errorf("Wanted %d problems, got %d", want, got)
+		return
+	}
+	if got, want := problems[0].Message, "Org deps of the bot author policy is not defined in the MAINTAINERS file"; got != want {
+		t.Errorf("Wanted problem %q, got %q", want, got)
+	}
+}
+
+func TestPolicyEnforceAuthors(t *testing.T) {
+	config, _ := ParseConfigStr(configAuthors)
+	config.Enforce(&Policy{Approvals: 2}, "octocat")
+
+	if got, want := config.Authors[AuthorBot].Approvals, 2; got != want {
+		t.Errorf("Wanted bot approvals %d, got %d", want, got)
+	}
+	if got, want := config.Authors[AuthorExternal].Approvals, 3; got != want {
+		t.Errorf("Wanted external approvals %d, got %d", want, got)
+	}
+	if got, want := len(config.Overrides), 1; got != want {
+		t.Errorf("Wanted %d overrides, got %d", want, got)
+	}
+}
+
+var configAuthors = `
+approvals = 2
+
+[authors.external]
+approvals = 3
+
+[authors.first_time]
+team = "core"
+
+[authors.bot]
+approvals = 1
+team = "deps"
+logins = ["dependabot[bot]"]
+`
@@ -0,0 +1,63 @@
+package model
+
+import (
+	"path"
+	"sort"
+)
+
+// Branch represents the approval policy of the target branches
+// matching a glob pattern in the .lgtm file.
+type Branch struct {
+	Approvals int `json:"approvals" toml:"approvals" yaml:"approvals"`
+}
+
+// ForBranch returns the configuration that applies to pull requests
+// targeting the named branch. An exact match takes precedence over
+// glob patterns, and otherwise the longest matching pattern is used.
+func (c *Config) ForBranch(name string) *Config {
+	var match string
+	for pattern := range c.Branches {
+		if pattern == name {
+			match = pattern
+			break
+		}
+		ok, err := path.Match(pattern, name)
+		if err != nil || !ok {
+			continue
+		}
+		if len(pattern) > len(match) || (len(pattern) == len(match) && pattern < match) {
+			match = pattern
+		}
+	}
+	branch, ok := c.Branches[match]
+	if !ok {
+		return c
+	}
+	config := *c
+	if branch.Approvals != 0 {
+		config.Approvals = branch.Approvals
+	}
+	return &config
+}
+
+// BranchPatterns returns the sorted list of branch patterns
+// with an approval policy.
+func (c *Config) BranchPatterns() []string {
+	var patterns []string
+	for pattern := range c.Branches {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+	return patterns
+}
+
+// MatchBranch returns true if the branch name matches one
+// of the glob patterns.
+func MatchBranch(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,58 @@
+package model
+
+import "testing"
+
+func TestConfigBranches(t *testing.T) {
+	config, err := ParseConfigStr(configBranches)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	var tests = []struct {
+		branch    string
+		approvals int
+	}{
+		{"main", 2},
+		{"release/1.0", 3},
+		{"release/lts", 4},
+		{"feature/login", 1},
+	}
+	for _, test := range tests {
+		got := config.ForBranch(test.branch).Approvals
+		if got != test.approvals {
+			t.Errorf("Wanted %d approvals for branch %s, got %d", test.approvals, test.branch, got)
+		}
+	}
+	if config.Approvals != 1 {
+		t.Errorf("Wanted the configuration unchanged, got %d approvals", config.Approvals)
+	}
+
+	patterns := config.BranchPatterns()
+	if len(patterns) != 3 || patterns[0] != "main" {
+		t.Errorf("Wanted sorted branch patterns, got %v", patterns)
+	}
+	if !MatchBranch(patterns, "release/2.0") || MatchBranch(patterns, "feature/login") {
+		t.Errorf("Wanted release branches matched, and feature branches ignored")
+	}
+}
+
+func TestConfigBranchesInvalid(t *testing.T) {
+	_, err := ParseConfigStr("[branch.\"release/[\"]\napprovals = 3\n")
+	if err == nil {
+		t.Errorf("Wanted error for invalid branch pattern")
+	}
+}
+
+var configBranches = `
+approvals = 1
+
+[branch.main]
+approvals = 2
+
+[branch."release/*"]
+approvals = 3
+
+[branch."release/lts"]
+approvals = 4
+`
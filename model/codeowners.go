@@ -0,0 +1,189 @@
+package model
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// CodeOwnersPaths lists the locations of the CODEOWNERS file
+// in the repository, in the order they are searched.
+var CodeOwnersPaths = []string{
+	".github/CODEOWNERS",
+	"CODEOWNERS",
+	"docs/CODEOWNERS",
+}
+
+// CodeOwner represents an entry in the CODEOWNERS file.
+type CodeOwner struct {
+	Pattern string   `json:"pattern"`
+	Owners  []string `json:"owners"`
+}
+
+// CodeOwners represents a CODEOWNERS file.
+type CodeOwners struct {
+	Entries []*CodeOwner `json:"entries"`
+}
+
+// ParseCodeOwners parses a projects CODEOWNERS file.
+func ParseCodeOwners(data []byte) (*CodeOwners, error) {
+	return ParseCodeOwnersStr(string(data))
+}
+
+// ParseCodeOwnersStr parses a projects CODEOWNERS file in string format.
+func ParseCodeOwnersStr(data string) (*CodeOwners, error) {
+	c := new(CodeOwners)
+	buf := bufio.NewScanner(strings.NewReader(data))
+	for num := 1; buf.Scan(); num++ {
+		line := strings.TrimSpace(buf.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		entry := &CodeOwner{
+			Pattern: strings.TrimPrefix(fields[0], "\\"),
+		}
+		for _, owner := range fields[1:] {
+			if strings.HasPrefix(owner, "#") {
+				break
+			}
+			if !strings.Contains(owner, "@") {
+				return nil, fmt.Errorf("Invalid owner %s on line %d", owner, num)
+			}
+			entry.Owners = append(entry.Owners, owner)
+		}
+		c.Entries = append(c.Entries, entry)
+	}
+	return c, buf.Err()
+}
+
+// Owners returns the owners of the file path. The last matching
+// entry in the file takes precedence, and may have no owners.
+func (c *CodeOwners) Owners(file string) []string {
+	for i := len(c.Entries) - 1; i >= 0; i-- {
+		entry := c.Entries[i]
+		if matchOwner(entry.Pattern, file) {
+			return entry.Owners
+		}
+	}
+	return nil
+}
+
+// Teams returns the org/team pairs of the teams listed as owners.
+func (c *CodeOwners) Teams() []string {
+	var teams []string
+	seen := map[string]bool{}
+	for _, entry := range c.Entries {
+		for _, owner := range entry.Owners {
+			if isOwnerTeam(owner) && !seen[owner] {
+				seen[owner] = true
+				teams = append(teams, strings.TrimPrefix(owner, "@"))
+			}
+		}
+	}
+	return teams
+}
+
+// Rules returns an approval rule for each set of owners of the changed
+// files, requiring an approval from one of the owners. Owners are matched
+// by login, so owners listed by email address are ignored.
+func (c *CodeOwners) Rules(files []string) []*Rule {
+	var rules []*Rule
+	rulem := map[string]*Rule{}
+	for _, file := range files {
+		name := ownerSet(c.Owners(file))
+		if len(name) == 0 {
+			continue
+		}
+		rule, ok := rulem[name]
+		if !ok {
+			rule = &Rule{
+				Name:      name,
+				Approvals: 1,
+				Team:      name,
+			}
+			rulem[name] = rule
+			rules = append(rules, rule)
+		}
+		// the rule paths are patterns, so the file name is escaped
+		// to match only the changed file itself.
+		rule.Paths = append(rule.Paths, escapePath(file))
+	}
+	return rules
+}
+
+// escapePath is a helper function that escapes the pattern
+// metacharacters in the file name.
+func escapePath(file string) string {
+	var buf bytes.Buffer
+	for _, r := range file {
+		if strings.ContainsRune(`*?[]\`, r) {
+			buf.WriteByte('\\')
+		}
+		buf.WriteRune(r)
+	}
+	return buf.String()
+}
+
+// Maintainer returns the owners as a Maintainer file, with an org section
+// for each set of owners and each team. The members map holds the logins
+// of each team, keyed by org/team pair.
+func (c *CodeOwners) Maintainer(members map[string][]string) *Maintainer {
+	m := new(Maintainer)
+	m.Org = map[string]*Org{}
+	m.People = map[string]*Person{}
+	for _, entry := range c.Entries {
+		name := ownerSet(entry.Owners)
+		if len(name) == 0 {
+			continue
+		}
+		var logins []string
+		for _, owner := range entry.Owners {
+			switch {
+			case isOwnerTeam(owner):
+				team := members[strings.TrimPrefix(owner, "@")]
+				m.Org[owner] = &Org{team}
+				logins = append(logins, team...)
+			case strings.HasPrefix(owner, "@"):
+				logins = append(logins, strings.TrimPrefix(owner, "@"))
+			}
+		}
+		for _, login := range logins {
+			m.People[login] = &Person{Login: login}
+		}
+		m.Org[name] = &Org{logins}
+	}
+	return m
+}
+
+// ownerSet is a helper function that returns the name of a set
+// of owners, ignoring the owners listed by email address.
+func ownerSet(owners []string) string {
+	var names []string
+	for _, owner := range owners {
+		if strings.HasPrefix(owner, "@") {
+			names = append(names, owner)
+		}
+	}
+	return strings.Join(names, " ")
+}
+
+// isOwnerTeam is a helper function that returns true if the
+// owner is an @org/team entry.
+func isOwnerTeam(owner string) bool {
+	return strings.HasPrefix(owner, "@") && strings.Contains(owner, "/")
+}
+
+// matchOwner is a helper function that reports whether the file path
+// matches a CODEOWNERS pattern. Patterns follow the gitignore rules, so
+// a pattern without a slash matches at any depth, and a pattern matching
+// a directory matches every file beneath it.
+func matchOwner(pattern, file string) bool {
+	anchored := strings.Contains(strings.TrimSuffix(pattern, "/"), "/")
+	pattern = strings.Trim(pattern, "/")
+	if !anchored {
+		pattern = "**/" + pattern
+	}
+	return matchPath(pattern+"/**", file)
+}
@@ -0,0 +1,117 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseCodeOwners(t *testing.T) {
+	owners, err := ParseCodeOwnersStr(codeOwnersFile)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(owners.Entries) != 6 {
+		t.Errorf("Wanted 6 entries, got %d", len(owners.Entries))
+		return
+	}
+
+	var tests = []struct {
+		file   string
+		owners []string
+	}{
+		{"main.go", []string{"@bradrydzewski"}},
+		{"README.md", []string{"@octocat/docs", "docs@example.com"}},
+		{"docs/index.md", []string{"@octocat/docs", "docs@example.com"}},
+		{"pkg/crypto/aes/aes.go", []string{"@octocat/security", "@mattnorris"}},
+		{"build/logs/output.txt", []string{"@octocat/release"}},
+		{"src/build/logs/output.txt", []string{"@bradrydzewski"}},
+		{"vendor/github.com/pkg/errors/errors.go", nil},
+	}
+	for _, test := range tests {
+		got := owners.Owners(test.file)
+		if !reflect.DeepEqual(got, test.owners) {
+			t.Errorf("Wanted owners %v for %s, got %v", test.owners, test.file, got)
+		}
+	}
+
+	teams := owners.Teams()
+	want := []string{"octocat/docs", "octocat/security", "octocat/release"}
+	if !reflect.DeepEqual(teams, want) {
+		t.Errorf("Wanted teams %v, got %v", want, teams)
+	}
+}
+
+func TestParseCodeOwnersInvalid(t *testing.T) {
+	_, err := ParseCodeOwnersStr("*.go bradrydzewski")
+	if err == nil {
+		t.Errorf("Wanted error for owner without @")
+	}
+}
+
+func TestCodeOwnersRules(t *testing.T) {
+	owners, _ := ParseCodeOwnersStr(codeOwnersFile)
+	rules := owners.Rules([]string{
+		"main.go",
+		"cmd/main.go",
+		"pkg/crypto/aes/aes.go",
+		"vendor/github.com/pkg/errors/errors.go",
+	})
+	if len(rules) != 2 {
+		t.Errorf("Wanted 2 rules, got %d", len(rules))
+		return
+	}
+	if rules[0].Name != "@bradrydzewski" || len(rules[0].Paths) != 2 {
+		t.Errorf("Wanted rule @bradrydzewski with 2 paths, got %s with %d", rules[0].Name, len(rules[0].Paths))
+	}
+	if rules[1].Approvals != 1 {
+		t.Errorf("Wanted 1 approval, got %d", rules[1].Approvals)
+	}
+
+	maintainer := owners.Maintainer(map[string][]string{
+		"octocat/security": {"janedoe"},
+	})
+	subset, err := FromOrg(maintainer, rules[1].Team)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	for _, login := range []string{"janedoe", "mattnorris"} {
+		if _, ok := subset.People[login]; !ok {
+			t.Errorf("Wanted owner %s", login)
+		}
+	}
+	if _, ok := subset.People["bradrydzewski"]; ok {
+		t.Errorf("Wanted owner bradrydzewski excluded")
+	}
+}
+
+func TestCodeOwnersRulesEscaped(t *testing.T) {
+	owners, _ := ParseCodeOwnersStr(codeOwnersFile)
+	files := []string{"docs/[draft].md", "docs/*?.md"}
+	rules := owners.Rules(files)
+	if len(rules) != 1 {
+		t.Errorf("Wanted 1 rule, got %d", len(rules))
+		return
+	}
+	for _, file := range files {
+		if !rules[0].IsMatch(file) {
+			t.Errorf("Wanted rule %s to match %s", rules[0].Name, file)
+		}
+	}
+	if rules[0].IsMatch("docs/d.md") {
+		t.Errorf("Wanted rule %s to match only the changed files", rules[0].Name)
+	}
+}
+
+var codeOwnersFile = `
+# default owners
+*       @bradrydzewski
+
+*.md    @octocat/docs docs@example.com
+/docs/  @octocat/docs docs@example.com # documentation
+
+pkg/crypto/ @octocat/security @mattnorris
+build/logs/ @octocat/release
+/vendor/
+`
@@ -1,6 +1,9 @@
 package model
 
+import "time"
+
 type Comment struct {
-	Author string
-	Body   string
+	Author  string
+	Body    string
+	Created time.Time
 }
@@ -0,0 +1,9 @@
+package model
+
+import "time"
+
+// Commit represents the head commit of a pull request.
+type Commit struct {
+	SHA     string
+	Created time.Time
+}
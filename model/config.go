@@ -1,40 +1,171 @@
 package model
 
 import (
+	"fmt"
+	"path"
 	"regexp"
+	"time"
 
-	"github.com/BurntSushi/toml"
 	"github.com/ianschenck/envflag"
 )
 
 type Config struct {
-	Approvals       int    `json:"approvals"         toml:"approvals"`
-	Pattern         string `json:"pattern"           toml:"pattern"`
-	Team            string `json:"team"              toml:"team"`
-	SelfApprovalOff bool   `json:"self_approval_off" toml:"self_approval_off"`
+	Approvals       int     `json:"approvals"         toml:"approvals"         yaml:"approvals"`
+	Pattern         string  `json:"pattern"           toml:"pattern"           yaml:"pattern"`
+	Team            string  `json:"team"              toml:"team"              yaml:"team"`
+	SelfApprovalOff bool    `json:"self_approval_off" toml:"self_approval_off" yaml:"self_approval_off"`
+	ResetOnPush     bool    `json:"reset_on_push"     toml:"reset_on_push"     yaml:"reset_on_push"`
+	BlockPattern    string  `json:"block_pattern"     toml:"block_pattern"     yaml:"block_pattern"`
+	LiftPattern     string  `json:"lift_pattern"      toml:"lift_pattern"      yaml:"lift_pattern"`
+	RetractPattern  string  `json:"retract_pattern"   toml:"retract_pattern"   yaml:"retract_pattern"`
+	Source          string  `json:"source"            toml:"source"            yaml:"source"`
+	WipPattern      string  `json:"wip_pattern"       toml:"wip_pattern"       yaml:"wip_pattern"`
+	WipDraft        bool    `json:"wip_draft"         toml:"wip_draft"         yaml:"wip_draft"`
+	MinOpenDuration string  `json:"min_open_duration" toml:"min_open_duration" yaml:"min_open_duration"`
+	Rules           []*Rule `json:"rules,omitempty"   toml:"rule"              yaml:"rules"`
 
-	re *regexp.Regexp
+	Branches map[string]*Branch `json:"branches,omitempty" toml:"branch" yaml:"branches"`
+	Guard    *Guard             `json:"guard,omitempty"    toml:"guard"  yaml:"guard"`
+
+	// Orgs sets the number of approvals required from each org
+	// section of the MAINTAINERS file, for example:
+	//
+	//	[orgs]
+	//	core = 2
+	//	security = 1
+	//
+	Orgs map[string]int `json:"orgs,omitempty" toml:"orgs" yaml:"orgs"`
+
+	// Authors sets the approval policy of pull requests by external,
+	// first-time and allowlisted bot authors.
+	Authors map[string]*AuthorPolicy `json:"authors,omitempty" toml:"authors" yaml:"authors"`
+
+	// Overrides lists the settings raised by the server policy,
+	// which repositories cannot weaken.
+	Overrides []string `json:"overrides,omitempty" toml:"-" yaml:"-"`
+
+	// Path is the location of the .lgtm file in the repository,
+	// or empty when the repository has no .lgtm file.
+	Path string `json:"path,omitempty" toml:"-" yaml:"-"`
+
+	re      *regexp.Regexp
+	blockre *regexp.Regexp
+	liftre  *regexp.Regexp
+	unre    *regexp.Regexp
+	wipre   *regexp.Regexp
+	minOpen time.Duration
+
+	// team is the org section of the MAINTAINERS file approving
+	// the default rule, when set by an author policy.
+	team string
+
+	// floor requires the top level approvals in addition to the
+	// org quotas, when set by the server policy.
+	floor bool
+
+	// minimum is the minimum approvals set by the server policy,
+	// which weighted approvals cannot satisfy on their own.
+	minimum int
 }
 
+// Approver sources select the file listing the approvers.
+const (
+	SourceMaintainers = "maintainers"
+	SourceCodeOwners  = "codeowners"
+)
+
 var (
-	approvals = envflag.Int("LGTM_APPROVALS", 2, "")
-	pattern = envflag.String("LGTM_PATTERN", "(?i)LGTM", "")
-	team = envflag.String("LGTM_TEAM", "MAINTAINERS", "")
+	approvals       = envflag.Int("LGTM_APPROVALS", 2, "")
+	pattern         = envflag.String("LGTM_PATTERN", "(?i)LGTM", "")
+	team            = envflag.String("LGTM_TEAM", "MAINTAINERS", "")
 	selfApprovalOff = envflag.Bool("LGTM_SELF_APPROVAL_OFF", false, "")
+	resetOnPush     = envflag.Bool("LGTM_RESET_ON_PUSH", false, "")
+	source          = envflag.String("LGTM_SOURCE", SourceMaintainers, "")
+	blockPattern    = envflag.String("LGTM_BLOCK_PATTERN", "", "")
+	liftPattern     = envflag.String("LGTM_LIFT_PATTERN", "", "")
+	retractPattern  = envflag.String("LGTM_RETRACT_PATTERN", "(?i)^(un-?lgtm|/lgtm cancel)", "")
+	wipPattern      = envflag.String("LGTM_WIP_PATTERN", "", "")
+	wipDraft        = envflag.Bool("LGTM_WIP_DRAFT", false, "")
+	minOpenDuration = envflag.String("LGTM_MIN_OPEN_DURATION", "", "")
+	defaultRepo     = envflag.String("LGTM_DEFAULT_REPO", ".github", "")
 )
 
-// ParseConfig parses a projects .lgtm file
+// ParseConfig parses a projects .lgtm file in the TOML, YAML or
+// JSON format, sniffed from its contents.
 func ParseConfig(data []byte) (*Config, error) {
-	return ParseConfigStr(string(data))
+	return parseConfig(configFile{data: data})
 }
 
 // ParseConfigStr parses a projects .lgtm file in string format.
 func ParseConfigStr(data string) (*Config, error) {
+	return ParseConfig([]byte(data))
+}
+
+// ParseConfigFile parses a projects .lgtm file in the format
+// of the file name extension, for example .lgtm.yml.
+func ParseConfigFile(name string, data []byte) (*Config, error) {
+	return parseConfig(configFile{name, data})
+}
+
+// ParseConfigDefault parses a projects .lgtm file, inheriting the
+// settings it does not define from the organization-wide default
+// .lgtm file. Settings defined in the projects file take precedence,
+// branch and org tables are merged by name, and rules defined in the
+// projects file replace the default rules. Each file is parsed in the
+// format of its name, or sniffed from its contents when unnamed.
+func ParseConfigDefault(defaultsName string, defaults []byte, name string, data []byte) (*Config, error) {
+	return parseConfig(configFile{defaultsName, defaults}, configFile{name, data})
+}
+
+// configFile represents a .lgtm file and its name, which
+// determines the format of the file.
+type configFile struct {
+	name string
+	data []byte
+}
+
+// DefaultRepo returns the repository of the owner holding the
+// organization-wide default .lgtm file, or nil when organization-wide
+// defaults are disabled.
+func DefaultRepo(owner string) *Repo {
+	if len(*defaultRepo) == 0 {
+		return nil
+	}
+	return &Repo{
+		Owner: owner,
+		Name:  *defaultRepo,
+		Slug:  owner + "/" + *defaultRepo,
+	}
+}
+
+// parseConfig is a helper function that parses the .lgtm files in
+// order of increasing precedence, falling back to the environment
+// for settings not defined in any file.
+func parseConfig(files ...configFile) (*Config, error) {
 	c := new(Config)
-	_, err := toml.Decode(data, c)
-	if err != nil {
-		return nil, err
+	for _, file := range files {
+		format := FormatOf(file.name, file.data)
+		keys := map[string]interface{}{}
+		if err := decode(format, file.data, &keys); err != nil {
+			return nil, err
+		}
+		// the decoders fill an existing slice in place, so the
+		// inherited rules are dropped before they are replaced.
+		_, table := keys["rule"]
+		_, list := keys["rules"]
+		if table || list {
+			c.Rules = nil
+		}
+		if err := decode(format, file.data, c); err != nil {
+			return nil, err
+		}
 	}
+	// overrides are recorded by the server policy, and the path
+	// by the caller, and neither is read from the file.
+	c.Overrides = nil
+	c.Path = ""
+
+	var err error
 	if c.Approvals == 0 {
 		c.Approvals = *approvals
 	}
@@ -47,6 +178,88 @@ func ParseConfigStr(data string) (*Config, error) {
 	if c.SelfApprovalOff == false {
 		c.SelfApprovalOff = *selfApprovalOff
 	}
+	if c.ResetOnPush == false {
+		c.ResetOnPush = *resetOnPush
+	}
+	if len(c.BlockPattern) == 0 {
+		c.BlockPattern = *blockPattern
+	}
+	if len(c.LiftPattern) == 0 {
+		c.LiftPattern = *liftPattern
+	}
+	if len(c.RetractPattern) == 0 {
+		c.RetractPattern = *retractPattern
+	}
+	if len(c.WipPattern) == 0 {
+		c.WipPattern = *wipPattern
+	}
+	if c.WipDraft == false {
+		c.WipDraft = *wipDraft
+	}
+	if len(c.MinOpenDuration) == 0 {
+		c.MinOpenDuration = *minOpenDuration
+	}
+	if len(c.Source) == 0 {
+		c.Source = *source
+	}
+	if c.Source != SourceMaintainers && c.Source != SourceCodeOwners {
+		return nil, fmt.Errorf("Invalid approver source %s", c.Source)
+	}
+	for pattern := range c.Branches {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("Invalid branch pattern %s. %s", pattern, err)
+		}
+	}
+	for org, approvals := range c.Orgs {
+		if approvals < 1 {
+			return nil, fmt.Errorf("Invalid number of approvals for org %s", org)
+		}
+	}
+	if err := c.validateAuthors(); err != nil {
+		return nil, err
+	}
+	for i, rule := range c.Rules {
+		if len(rule.Name) == 0 {
+			rule.Name = fmt.Sprintf("rule%d", i+1)
+		}
+		if rule.Approvals == 0 {
+			rule.Approvals = c.Approvals
+		}
+	}
+
+	if len(c.BlockPattern) != 0 {
+		c.blockre, err = regexp.Compile(c.BlockPattern)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(c.LiftPattern) != 0 {
+		c.liftre, err = regexp.Compile(c.LiftPattern)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(c.RetractPattern) != 0 {
+		c.unre, err = regexp.Compile(c.RetractPattern)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(c.WipPattern) != 0 {
+		c.wipre, err = regexp.Compile(c.WipPattern)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(c.MinOpenDuration) != 0 {
+		c.minOpen, err = time.ParseDuration(c.MinOpenDuration)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid min_open_duration %s. %s", c.MinOpenDuration, err)
+		}
+		if c.minOpen < 0 {
+			return nil, fmt.Errorf("Invalid min_open_duration %s. Duration must not be negative.", c.MinOpenDuration)
+		}
+	}
 
 	c.re, err = regexp.Compile(c.Pattern)
 	return c, err
@@ -61,3 +274,47 @@ func (c *Config) IsMatch(text string) bool {
 	}
 	return c.re.MatchString(text)
 }
+
+// IsBlock returns true if the text matches the block pattern,
+// vetoing the pull request.
+func (c *Config) IsBlock(text string) bool {
+	return c.blockre != nil && c.blockre.MatchString(text)
+}
+
+// IsLift returns true if the text matches the lift pattern,
+// lifting a veto of the pull request.
+func (c *Config) IsLift(text string) bool {
+	return c.liftre != nil && c.liftre.MatchString(text)
+}
+
+// IsRetract returns true if the text matches the retract pattern,
+// taking back an earlier approval of the pull request.
+func (c *Config) IsRetract(text string) bool {
+	return c.unre != nil && c.unre.MatchString(text)
+}
+
+// MinOpen returns the minimum time a pull request is open before
+// it can be approved, or zero if there is no review window.
+func (c *Config) MinOpen() time.Duration {
+	return c.minOpen
+}
+
+// ReviewWindow returns the time the review window of a pull request
+// opened at the given time ends, or the zero time if there is no
+// review window.
+func (c *Config) ReviewWindow(opened time.Time) time.Time {
+	if c.minOpen == 0 || opened.IsZero() {
+		return time.Time{}
+	}
+	return opened.Add(c.minOpen)
+}
+
+// IsWorkInProgress returns true if the pull request title matches
+// the wip pattern, or the pull request is a draft and drafts are
+// treated as work in progress.
+func (c *Config) IsWorkInProgress(issue *Issue) bool {
+	if c.WipDraft && issue.Draft {
+		return true
+	}
+	return c.wipre != nil && c.wipre.MatchString(issue.Title)
+}
@@ -0,0 +1,120 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseConfigDefault(t *testing.T) {
+	config, err := ParseConfigDefault("", []byte(configDefault), ".lgtm", []byte(configRepo))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if got, want := config.Approvals, 1; got != want {
+		t.Errorf("Wanted approvals %d, got %d", want, got)
+	}
+	if got, want := config.Team, "core"; got != want {
+		t.Errorf("Wanted team %s, got %s", want, got)
+	}
+	if got, want := config.Orgs["core"], 2; got != want {
+		t.Errorf("Wanted %d approvals from core, got %d", want, got)
+	}
+	if got, want := config.Orgs["security"], 1; got != want {
+		t.Errorf("Wanted %d approvals from security, got %d", want, got)
+	}
+	if got, want := len(config.Rules), 1; got != want {
+		t.Errorf("Wanted %d rules, got %d", want, got)
+		return
+	}
+	if got, want := config.Rules[0].Name, "docs"; got != want {
+		t.Errorf("Wanted rule %s, got %s", want, got)
+	}
+}
+
+func TestConfigWorkInProgress(t *testing.T) {
+	config, err := ParseConfigStr(configWip)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	var tests = []struct {
+		issue *Issue
+		want  bool
+	}{
+		{&Issue{Title: "WIP: add feature"}, true},
+		{&Issue{Title: "[WIP] add feature"}, true},
+		{&Issue{Title: "add feature"}, false},
+		{&Issue{Title: "add feature", Draft: true}, true},
+	}
+	for _, test := range tests {
+		if got := config.IsWorkInProgress(test.issue); got != test.want {
+			t.Errorf("Wanted work in progress %v for %q, got %v", test.want, test.issue.Title, got)
+		}
+	}
+
+	config, _ = ParseConfigStr("")
+	if config.IsWorkInProgress(&Issue{Title: "WIP", Draft: true}) {
+		t.Errorf("Wanted work in progress disabled by default")
+	}
+}
+
+func TestConfigReviewWindow(t *testing.T) {
+	config, err := ParseConfigStr("min_open_duration = \"2h\"\n")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	opened := time.Date(2016, 5, 1, 10, 0, 0, 0, time.UTC)
+	if got, want := config.ReviewWindow(opened), opened.Add(2*time.Hour); !got.Equal(want) {
+		t.Errorf("Wanted review window to end at %s, got %s", want, got)
+	}
+	if got := config.ReviewWindow(time.Time{}); !got.IsZero() {
+		t.Errorf("Wanted no review window without an open time, got %s", got)
+	}
+
+	config, _ = ParseConfigStr("")
+	if got := config.ReviewWindow(opened); !got.IsZero() {
+		t.Errorf("Wanted no review window by default, got %s", got)
+	}
+
+	for _, duration := range []string{"2", "-1h"} {
+		_, err = ParseConfigStr("min_open_duration = \"" + duration + "\"\n")
+		if err == nil {
+			t.Errorf("Wanted error for min_open_duration %s", duration)
+		}
+	}
+}
+
+var configDefault = `
+approvals = 2
+team = "core"
+
+[orgs]
+core = 1
+security = 1
+
+[[rule]]
+name = "api"
+paths = ["api/**"]
+
+[[rule]]
+name = "web"
+paths = ["web/**"]
+`
+
+var configRepo = `
+approvals = 1
+
+[orgs]
+core = 2
+
+[[rule]]
+name = "docs"
+paths = ["docs/**"]
+`
+
+var configWip = `
+wip_pattern = '^(WIP|\[WIP\])'
+wip_draft = true
+`
@@ -0,0 +1,162 @@
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v2"
+)
+
+// Formats of the .lgtm and MAINTAINERS files. A MAINTAINERS file may
+// also use the line-based text format.
+const (
+	FormatTOML = "toml"
+	FormatYAML = "yaml"
+	FormatJSON = "json"
+)
+
+// FormatOf returns the format of the named file from its extension,
+// or sniffed from its contents when the extension is not recognized.
+// An empty string is returned when the format cannot be determined.
+func FormatOf(name string, data []byte) string {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".toml":
+		return FormatTOML
+	case ".yml", ".yaml":
+		return FormatYAML
+	case ".json":
+		return FormatJSON
+	}
+	return sniffFormat(data)
+}
+
+// sniffFormat is a helper function that determines the format of the
+// file from its contents. A JSON file is an object, and otherwise TOML
+// is preferred over YAML, since the formats overlap.
+func sniffFormat(data []byte) string {
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		return FormatJSON
+	}
+	v := map[string]interface{}{}
+	if _, err := toml.Decode(string(data), &v); err == nil {
+		return FormatTOML
+	}
+	if err := yaml.Unmarshal(data, &v); err == nil {
+		return FormatYAML
+	}
+	return ""
+}
+
+// decode is a helper function that decodes the file in the format,
+// defaulting to TOML when the format is unknown.
+func decode(format string, data []byte, v interface{}) error {
+	switch format {
+	case FormatJSON:
+		return json.Unmarshal(data, v)
+	case FormatYAML:
+		return yaml.Unmarshal(data, v)
+	default:
+		_, err := toml.Decode(string(data), v)
+		return err
+	}
+}
+
+// decodeStrict is a helper function that decodes the file in the format,
+// and returns the keys of the file that do not match a field of v.
+func decodeStrict(format string, data []byte, v interface{}) ([]string, error) {
+	var keys []string
+	switch format {
+	case FormatJSON, FormatYAML:
+		if err := decode(format, data, v); err != nil {
+			return nil, err
+		}
+		var generic interface{}
+		if err := decode(format, data, &generic); err != nil {
+			return nil, err
+		}
+		keys = unknownKeys("", generic, reflect.TypeOf(v), format)
+	default:
+		md, err := toml.Decode(string(data), v)
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range md.Undecoded() {
+			keys = append(keys, key.String())
+		}
+	}
+	return keys, nil
+}
+
+// unknownKeys is a helper function that walks the decoded file, and
+// returns the keys that do not match a field of the type, named by
+// the struct tag of the format.
+func unknownKeys(prefix string, v interface{}, t reflect.Type, tag string) []string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	var keys []string
+	switch t.Kind() {
+	case reflect.Struct:
+		fields := map[string]reflect.Type{}
+		for i := 0; i < t.NumField(); i++ {
+			name := strings.Split(t.Field(i).Tag.Get(tag), ",")[0]
+			if len(name) != 0 && name != "-" {
+				fields[name] = t.Field(i).Type
+			}
+		}
+		entries := mapEntries(v)
+		for _, key := range sortedKeys(entries) {
+			field, ok := fields[key]
+			if !ok {
+				keys = append(keys, prefix+key)
+				continue
+			}
+			keys = append(keys, unknownKeys(prefix+key+".", entries[key], field, tag)...)
+		}
+	case reflect.Map:
+		entries := mapEntries(v)
+		for _, key := range sortedKeys(entries) {
+			keys = append(keys, unknownKeys(prefix+key+".", entries[key], t.Elem(), tag)...)
+		}
+	case reflect.Slice:
+		items, _ := v.([]interface{})
+		for _, item := range items {
+			keys = append(keys, unknownKeys(prefix, item, t.Elem(), tag)...)
+		}
+	}
+	return keys
+}
+
+// mapEntries is a helper function that returns the entries of a decoded
+// JSON or YAML object, keyed by string.
+func mapEntries(v interface{}) map[string]interface{} {
+	entries := map[string]interface{}{}
+	switch m := v.(type) {
+	case map[string]interface{}:
+		for key, value := range m {
+			entries[key] = value
+		}
+	case map[interface{}]interface{}:
+		for key, value := range m {
+			entries[fmt.Sprint(key)] = value
+		}
+	}
+	return entries
+}
+
+// sortedKeys is a helper function that returns the sorted keys
+// of the decoded object.
+func sortedKeys(entries map[string]interface{}) []string {
+	var keys []string
+	for key := range entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,198 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFormatOf(t *testing.T) {
+	var tests = []struct {
+		name, data, want string
+	}{
+		{".lgtm.yml", "", FormatYAML},
+		{".lgtm.yaml", "", FormatYAML},
+		{".lgtm.json", "", FormatJSON},
+		{"lgtm.toml", "", FormatTOML},
+		{".lgtm", configFileToml, FormatTOML},
+		{".lgtm", configFileYaml, FormatYAML},
+		{".lgtm", configFileJson, FormatJSON},
+		{"MAINTAINERS", maintainerFile, ""},
+	}
+	for _, test := range tests {
+		got := FormatOf(test.name, []byte(test.data))
+		if got != test.want {
+			t.Errorf("Wanted format %q for %s, got %q", test.want, test.name, got)
+		}
+	}
+}
+
+func TestParseConfigFormats(t *testing.T) {
+	want, err := ParseConfigStr(configFileToml)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	var files = []struct {
+		name, data string
+	}{
+		{".lgtm", configFileYaml},
+		{".lgtm.yml", configFileYaml},
+		{".lgtm", configFileJson},
+		{".lgtm.json", configFileJson},
+	}
+	for _, file := range files {
+		got, err := ParseConfigFile(file.name, []byte(file.data))
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if got.Approvals != want.Approvals || got.Pattern != want.Pattern || got.SelfApprovalOff != want.SelfApprovalOff {
+			t.Errorf("Wanted settings %v, got %v", want, got)
+		}
+		if !reflect.DeepEqual(got.Rules, want.Rules) {
+			t.Errorf("Wanted rules %v, got %v", want.Rules, got.Rules)
+		}
+		if !reflect.DeepEqual(got.Branches, want.Branches) {
+			t.Errorf("Wanted branches %v, got %v", want.Branches, got.Branches)
+		}
+		if !reflect.DeepEqual(got.Orgs, want.Orgs) {
+			t.Errorf("Wanted orgs %v, got %v", want.Orgs, got.Orgs)
+		}
+	}
+
+	_, err = ParseConfigFile(".lgtm.yml", []byte("approvals: [1"))
+	if err == nil {
+		t.Errorf("Wanted error for invalid YAML")
+	}
+}
+
+func TestParseMaintainerFormats(t *testing.T) {
+	want, err := ParseMaintainerStr(maintainerFileToml)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	var files = []struct {
+		name, data string
+	}{
+		{"MAINTAINERS", maintainerFileYaml},
+		{"MAINTAINERS.yml", maintainerFileYaml},
+		{"MAINTAINERS", maintainerFileJson},
+		{"MAINTAINERS.json", maintainerFileJson},
+	}
+	for _, file := range files {
+		got, err := ParseMaintainerFile(file.name, []byte(file.data))
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Wanted maintainers %v, got %v", want, got)
+		}
+	}
+
+	_, err = ParseMaintainerFile("MAINTAINERS.json", []byte(`{"org": {}}`))
+	if err == nil {
+		t.Errorf("Wanted error for missing people section")
+	}
+}
+
+func TestValidateFormats(t *testing.T) {
+	_, problems := ValidateConfig([]byte("approvals: 2\napproval: 1\nbranches:\n  master:\n    approval: 3\n"))
+	if len(problems) != 2 {
+		t.Errorf("Wanted 2 problems, got %d", len(problems))
+		return
+	}
+	if got := problems[0].Message; got != "Unknown key approval" {
+		t.Errorf("Wanted unknown key approval, got %s", got)
+	}
+	if got := problems[1].Message; got != "Unknown key branches.master.approval" {
+		t.Errorf("Wanted unknown key branches.master.approval, got %s", got)
+	}
+
+	_, problems = ValidateMaintainer([]byte(`{"people": {"bradrydzewski": {"logn": "brad"}}}`))
+	if len(problems) != 1 || problems[0].Message != "Unknown key people.bradrydzewski.logn" {
+		t.Errorf("Wanted unknown key people.bradrydzewski.logn, got %v", problems)
+	}
+
+	_, problems = ValidateMaintainer([]byte(`{"people": `))
+	if len(problems) != 1 || problems[0].Kind != ProblemSyntax {
+		t.Errorf("Wanted syntax problem, got %v", problems)
+	}
+}
+
+var configFileToml = `
+approvals = 1
+pattern = "(?i)ship it"
+self_approval_off = true
+
+[branch.master]
+approvals = 3
+
+[orgs]
+core = 1
+
+[[rule]]
+name = "docs"
+paths = ["docs/**"]
+approvals = 1
+`
+
+var configFileYaml = `
+approvals: 1
+pattern: "(?i)ship it"
+self_approval_off: true
+
+branches:
+  master:
+    approvals: 3
+
+orgs:
+  core: 1
+
+rules:
+  - name: docs
+    paths: ["docs/**"]
+    approvals: 1
+`
+
+var configFileJson = `{
+  "approvals": 1,
+  "pattern": "(?i)ship it",
+  "self_approval_off": true,
+  "branches": {"master": {"approvals": 3}},
+  "orgs": {"core": 1},
+  "rules": [{"name": "docs", "paths": ["docs/**"], "approvals": 1}]
+}`
+
+var maintainerFileYaml = `
+org:
+  core:
+    people: [mattnorris, bradrydzewski]
+
+people:
+  bradrydzewski:
+    name: Brad Rydzewski
+    email: brad.rydzewski@mail.com
+    login: bradrydzewski
+  mattnorris:
+    name: Matt Norris
+    email: matt.norris@mail.com
+    login: mattnorris
+`
+
+var maintainerFileJson = `{
+  "org": {"core": {"people": ["mattnorris", "bradrydzewski"]}},
+  "people": {
+    "bradrydzewski": {
+      "name": "Brad Rydzewski",
+      "email": "brad.rydzewski@mail.com",
+      "login": "bradrydzewski"
+    },
+    "mattnorris": {
+      "name": "Matt Norris",
+      "email": "matt.norris@mail.com",
+      "login": "mattnorris"
+    }
+  }
+}`
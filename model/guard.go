@@ -0,0 +1,39 @@
+package model
+
+// Guard represents the approvals required from a pull request that
+// changes the policy files, in addition to the approvals required
+// by the other rules, for example:
+//
+//	[guard]
+//	approvals = 1
+//	team = "admins"
+//
+// The approvals default to one approval from the members of the team
+// when a team is set, and otherwise to one more than the approvals.
+// The author of the pull request can never approve the change.
+type Guard struct {
+	Approvals int    `json:"approvals" toml:"approvals" yaml:"approvals"`
+	Team      string `json:"team"      toml:"team"      yaml:"team"`
+}
+
+// guardRule is a helper function that returns the rule guarding the
+// policy files, or nil if the policy files are not guarded.
+func (c *Config) guardRule() *Rule {
+	if c.Guard == nil {
+		return nil
+	}
+	approvals := c.Guard.Approvals
+	if approvals == 0 && len(c.Guard.Team) != 0 {
+		approvals = 1
+	}
+	if approvals == 0 {
+		approvals = c.Approvals + 1
+	}
+	return &Rule{
+		Name:      "guard",
+		Paths:     PolicyFiles(),
+		Approvals: approvals,
+		Team:      c.Guard.Team,
+		guard:     true,
+	}
+}
@@ -0,0 +1,51 @@
+package model
+
+import "testing"
+
+func TestConfigGuard(t *testing.T) {
+	config, err := ParseConfigStr("approvals = 2\n[guard]\nteam = \"admins\"\n")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	rules := config.Match([]string{"README.md"})
+	if got, want := len(rules), 1; got != want {
+		t.Errorf("Wanted %d rules, got %d", want, got)
+	}
+
+	rules = config.Match([]string{"MAINTAINERS"})
+	if got, want := len(rules), 2; got != want {
+		t.Errorf("Wanted %d rules, got %d", want, got)
+		return
+	}
+	if got, want := rules[0].Name, "default"; got != want {
+		t.Errorf("Wanted rule %s, got %s", want, got)
+	}
+	guard := rules[1]
+	if !guard.IsGuard() {
+		t.Errorf("Wanted guard rule")
+	}
+	if got, want := guard.Approvals, 1; got != want {
+		t.Errorf("Wanted %d guard approvals, got %d", want, got)
+	}
+	if got, want := guard.Team, "admins"; got != want {
+		t.Errorf("Wanted guard team %s, got %s", want, got)
+	}
+
+	config, _ = ParseConfigStr("approvals = 2\n[guard]\n")
+	rules = config.Match([]string{".github/CODEOWNERS", "README.md"})
+	if got, want := len(rules), 2; got != want {
+		t.Errorf("Wanted %d rules, got %d", want, got)
+		return
+	}
+	if got, want := rules[1].Approvals, 3; got != want {
+		t.Errorf("Wanted %d guard approvals, got %d", want, got)
+	}
+
+	for _, file := range []string{".github/lgtm.toml", ".lgtm.yml", ".github/MAINTAINERS"} {
+		rules = config.Match([]string{file})
+		if len(rules) != 2 || !rules[1].IsGuard() {
+			t.Errorf("Wanted guard rule for %s", file)
+		}
+	}
+}
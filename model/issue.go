@@ -1,7 +1,26 @@
 package model
 
+import "time"
+
 type Issue struct {
 	Number int
 	Title  string
 	Author string
+
+	// Branch is the target branch of the pull request. It is
+	// empty when not included in the hook payload.
+	Branch string
+
+	// Base is the commit of the target branch the pull request
+	// is compared against. It is empty when not included in the
+	// hook payload.
+	Base string
+
+	// Draft is true when the pull request is marked as a draft,
+	// which is not supported by every remote system.
+	Draft bool
+
+	// Created is the time the pull request was opened. It is
+	// zero when not included in the hook payload.
+	Created time.Time
 }
@@ -0,0 +1,11 @@
+package model
+
+// Job represents an evaluation of the approval status of a pull request
+// scheduled for a later time, such as the end of its review window. Jobs
+// are stored so that they are scheduled again when the server restarts.
+type Job struct {
+	ID     int64 `json:"id"     meddler:"job_id,pk"`
+	RepoID int64 `json:"-"      meddler:"job_repo_id"`
+	Number int   `json:"number" meddler:"job_number"`
+	Time   int64 `json:"time"   meddler:"job_time"`
+}
@@ -12,20 +12,42 @@ import (
 
 // Person represets an individual in the MAINTAINERS file.
 type Person struct {
-	Name  string `json:"name"  toml:"name"`
-	Email string `json:"email" toml:"email"`
-	Login string `json:"login" toml:"login"`
+	Name  string `json:"name"  toml:"name"  yaml:"name"`
+	Email string `json:"email" toml:"email" yaml:"email"`
+	Login string `json:"login" toml:"login" yaml:"login"`
+
+	// Weight is the number of approvals an approval from the
+	// person counts as. A person without a weight counts once.
+	Weight int `json:"weight,omitempty" toml:"weight" yaml:"weight"`
+}
+
+// Weight returns the summed weight of the approvals from
+// the list of people.
+func Weight(people []*Person) int {
+	var weight int
+	for _, person := range people {
+		if person.Weight == 0 {
+			weight++
+		} else {
+			weight += person.Weight
+		}
+	}
+	return weight
 }
 
 // Org represents a group, team or subset of users.
 type Org struct {
-	People []string `json:"people" toml:"people"`
+	People []string `json:"people" toml:"people" yaml:"people"`
 }
 
 // Maintainer represents a MAINTAINERS file.
 type Maintainer struct {
-	People map[string]*Person `json:"people"    toml:"people"`
-	Org    map[string]*Org    `json:"org"       toml:"org"`
+	People map[string]*Person `json:"people"    toml:"people" yaml:"people"`
+	Org    map[string]*Org    `json:"org"       toml:"org"    yaml:"org"`
+
+	// Path is the location of the MAINTAINERS file in the repository,
+	// or empty when the maintainers are the members of the team.
+	Path string `json:"path,omitempty" toml:"-" yaml:"-"`
 }
 
 // ParseMaintainer parses a projects MAINTAINERS file and returns
@@ -37,9 +59,27 @@ func ParseMaintainer(data []byte) (*Maintainer, error) {
 // ParseMaintainerStr parses a projects MAINTAINERS file in string
 // format and returns the list of maintainers.
 func ParseMaintainerStr(data string) (*Maintainer, error) {
-	m, err := parseMaintainerToml(data)
+	return ParseMaintainerFile("", []byte(data))
+}
+
+// ParseMaintainerFile parses a projects MAINTAINERS file in the format
+// of the file name extension, for example MAINTAINERS.yml, and returns
+// the list of maintainers. A file without a recognized extension may
+// use the TOML, YAML, JSON or text format.
+func ParseMaintainerFile(name string, data []byte) (*Maintainer, error) {
+	switch format := FormatOf(name, data); format {
+	case FormatJSON:
+		return parseMaintainerFormat(format, data)
+	case FormatYAML:
+		// a text file of bare logins is also valid YAML, which
+		// is only preferred when the people section is defined.
+		if m, err := parseMaintainerFormat(format, data); err == nil {
+			return m, nil
+		}
+	}
+	m, err := parseMaintainerToml(string(data))
 	if err != nil {
-		m, err = parseMaintainerText(data)
+		m, err = parseMaintainerText(string(data))
 		if err != nil {
 			return nil, err
 		}
@@ -89,6 +129,30 @@ func parseMaintainerToml(data string) (*Maintainer, error) {
 	if m.People == nil {
 		return nil, fmt.Errorf("Invalid Toml format. Missing people section.")
 	}
+	if err := maintainerLogins(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func parseMaintainerFormat(format string, data []byte) (*Maintainer, error) {
+	m := new(Maintainer)
+	if err := decode(format, data, m); err != nil {
+		return nil, err
+	}
+	if m.People == nil {
+		return nil, fmt.Errorf("Invalid %s format. Missing people section.", strings.ToUpper(format))
+	}
+	m.Path = ""
+	if err := maintainerLogins(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// maintainerLogins is a helper function that validates the people
+// section of the file, and defaults each persons Login value.
+func maintainerLogins(m *Maintainer) error {
 	// if the person is defined in the file, but the Login field is
 	// empty, we can use the map key as the Login value. This is mainly
 	// here to support Docker projects, which use GitHub instead of Login
@@ -96,8 +160,11 @@ func parseMaintainerToml(data string) (*Maintainer, error) {
 		if len(v.Login) == 0 {
 			v.Login = k
 		}
+		if v.Weight < 0 {
+			return fmt.Errorf("Invalid weight for %s. Weight must not be negative.", k)
+		}
 	}
-	return m, nil
+	return nil
 }
 
 func parseMaintainerText(data string) (*Maintainer, error) {
@@ -25,6 +25,27 @@ func TestParseMaintainer(t *testing.T) {
 	}
 }
 
+func TestMaintainerWeight(t *testing.T) {
+	parsed, err := ParseMaintainerStr(maintainerFileWeight)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	lead := parsed.People["bradrydzewski"]
+	if lead.Weight != 2 {
+		t.Errorf("Wanted weight 2, got %d", lead.Weight)
+	}
+	got := Weight([]*Person{lead, parsed.People["mattnorris"]})
+	if got != 3 {
+		t.Errorf("Wanted summed weight 3, got %d", got)
+	}
+
+	_, err = parseMaintainerToml("[people.bradrydzewski]\nweight = -1\n")
+	if err == nil {
+		t.Errorf("Wanted error for negative weight")
+	}
+}
+
 var people = []Person{
 	{Login: "bradrydzewski"},
 	{Login: "mattnorris"},
@@ -74,3 +95,14 @@ var maintainerFileToml = `
 	email = "matt.norris@mail.com"
 	login = "mattnorris"
 `
+
+var maintainerFileWeight = `
+[people]
+
+	[people.bradrydzewski]
+	login = "bradrydzewski"
+	weight = 2
+
+	[people.mattnorris]
+	login = "mattnorris"
+`
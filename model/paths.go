@@ -0,0 +1,47 @@
+package model
+
+import (
+	"strings"
+
+	"github.com/ianschenck/envflag"
+)
+
+var (
+	configPaths     = envflag.String("LGTM_CONFIG_PATHS", ".lgtm,.lgtm.yml,.lgtm.json,.github/lgtm.toml,.github/lgtm.yml", "")
+	maintainerPaths = envflag.String("LGTM_MAINTAINERS_PATHS", "MAINTAINERS,.github/MAINTAINERS,docs/MAINTAINERS", "")
+)
+
+// ConfigPaths returns the locations of the .lgtm file in the
+// repository, in the order they are searched.
+func ConfigPaths() []string {
+	return splitPaths(*configPaths)
+}
+
+// MaintainerPaths returns the locations of the MAINTAINERS file
+// in the repository, in the order they are searched.
+func MaintainerPaths() []string {
+	return splitPaths(*maintainerPaths)
+}
+
+// PolicyFiles returns the files defining the approval policy of a
+// repository, which are guarded when the .lgtm file has a guard.
+func PolicyFiles() []string {
+	var files []string
+	files = append(files, ConfigPaths()...)
+	files = append(files, MaintainerPaths()...)
+	files = append(files, CodeOwnersPaths...)
+	return files
+}
+
+// splitPaths is a helper function that splits the comma-separated
+// list of paths, ignoring empty entries.
+func splitPaths(list string) []string {
+	var paths []string
+	for _, path := range strings.Split(list, ",") {
+		path = strings.Trim(strings.TrimSpace(path), "/")
+		if len(path) != 0 {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
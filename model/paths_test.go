@@ -0,0 +1,17 @@
+package model
+
+import "testing"
+
+func TestSplitPaths(t *testing.T) {
+	got := splitPaths(" .github/lgtm.toml, .lgtm,,/MAINTAINERS/")
+	want := []string{".github/lgtm.toml", ".lgtm", "MAINTAINERS"}
+	if len(got) != len(want) {
+		t.Errorf("Wanted %d paths, got %d", len(want), len(got))
+		return
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Wanted path %s, got %s", want[i], got[i])
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package model
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Policy represents the minimum approval policy enforced by the server,
+// which the .lgtm file of a repository cannot weaken. The top level
+// settings apply to every repository, and owner sections raise them
+// further for the repositories of an owner, for example:
+//
+//	approvals = 2
+//	self_approval_off = true
+//
+//	[orgs]
+//	security = 1
+//
+//	[owner.octocat]
+//	approvals = 3
+type Policy struct {
+	Approvals       int            `json:"approvals,omitempty"         toml:"approvals"`
+	SelfApprovalOff bool           `json:"self_approval_off,omitempty" toml:"self_approval_off"`
+	Orgs            map[string]int `json:"orgs,omitempty"              toml:"orgs"`
+
+	Owners map[string]*Policy `json:"owners,omitempty" toml:"owner"`
+}
+
+// ParsePolicy parses the server policy file.
+func ParsePolicy(data []byte) (*Policy, error) {
+	return ParsePolicyStr(string(data))
+}
+
+// ParsePolicyStr parses the server policy file in string format.
+func ParsePolicyStr(data string) (*Policy, error) {
+	p := new(Policy)
+	_, err := toml.Decode(data, p)
+	if err != nil {
+		return nil, err
+	}
+	for owner, policy := range p.Owners {
+		if len(policy.Owners) != 0 {
+			return nil, fmt.Errorf("Invalid policy for owner %s. Owner sections cannot be nested", owner)
+		}
+	}
+	return p, nil
+}
+
+// ForOwner returns the policy that applies to the repositories of the
+// owner, combining the top level settings with the owner section.
+func (p *Policy) ForOwner(owner string) *Policy {
+	policy := &Policy{
+		Approvals:       p.Approvals,
+		SelfApprovalOff: p.SelfApprovalOff,
+		Orgs:            map[string]int{},
+	}
+	for org, approvals := range p.Orgs {
+		policy.Orgs[org] = approvals
+	}
+	section, ok := p.Owners[owner]
+	if !ok {
+		return policy
+	}
+	if section.Approvals > policy.Approvals {
+		policy.Approvals = section.Approvals
+	}
+	if section.SelfApprovalOff {
+		policy.SelfApprovalOff = true
+	}
+	for org, approvals := range section.Orgs {
+		if approvals > policy.Orgs[org] {
+			policy.Orgs[org] = approvals
+		}
+	}
+	return policy
+}
+
+// Enforce raises the configuration of a repository of the owner to the
+// minimum set by the policy, and records each setting it overrides.
+func (c *Config) Enforce(p *Policy, owner string) {
+	if p == nil {
+		return
+	}
+	p = p.ForOwner(owner)
+	c.minimum = p.Approvals
+
+	if c.Approvals < p.Approvals {
+		c.override("approvals raised from %d to %d", c.Approvals, p.Approvals)
+		c.Approvals = p.Approvals
+	}
+	for _, pattern := range c.BranchPatterns() {
+		branch := c.Branches[pattern]
+		if branch.Approvals != 0 && branch.Approvals < p.Approvals {
+			c.override("approvals for branch %s raised from %d to %d", pattern, branch.Approvals, p.Approvals)
+			branch.Approvals = p.Approvals
+		}
+	}
+	for _, rule := range c.Rules {
+		if rule.Approvals < p.Approvals {
+			c.override("approvals for rule %s raised from %d to %d", rule.Name, rule.Approvals, p.Approvals)
+			rule.Approvals = p.Approvals
+		}
+	}
+	for _, class := range c.authorClasses() {
+		author := c.Authors[class]
+		if author.Approvals != 0 && author.Approvals < p.Approvals {
+			c.override("approvals for %s authors raised from %d to %d", class, author.Approvals, p.Approvals)
+			author.Approvals = p.Approvals
+		}
+	}
+	if p.SelfApprovalOff && !c.SelfApprovalOff {
+		c.override("self approval turned off")
+		c.SelfApprovalOff = true
+	}
+
+	var orgs []string
+	for org := range p.Orgs {
+		orgs = append(orgs, org)
+	}
+	sort.Strings(orgs)
+
+	// quotas added to a repository without quotas, or quotas that
+	// sum to less than the minimum approvals, apply in addition to
+	// the approvals from any maintainer.
+	quota := len(c.Orgs) != 0
+	if len(orgs) != 0 && c.Orgs == nil {
+		c.Orgs = map[string]int{}
+	}
+	for _, org := range orgs {
+		if c.Orgs[org] < p.Orgs[org] {
+			c.override("approvals from org %s raised from %d to %d", org, c.Orgs[org], p.Orgs[org])
+			c.Orgs[org] = p.Orgs[org]
+		}
+	}
+	var sum int
+	for _, approvals := range c.Orgs {
+		sum += approvals
+	}
+	if len(c.Orgs) != 0 && (!quota || sum < p.Approvals) {
+		c.floor = true
+	}
+}
+
+// Granted returns the approvals granted by the people, summing their
+// weights in the MAINTAINERS file. The weights are set by the repository,
+// so while fewer people approve than the minimum approvals of the server
+// policy, each person counts once.
+func (c *Config) Granted(people []*Person) int {
+	weight := Weight(people)
+	if len(people) < c.minimum && weight > len(people) {
+		return len(people)
+	}
+	return weight
+}
+
+// override is a helper function that records a setting overridden
+// by the server policy.
+func (c *Config) override(format string, a ...interface{}) {
+	c.Overrides = append(c.Overrides, fmt.Sprintf(format, a...))
+}
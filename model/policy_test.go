@@ -0,0 +1,101 @@
+package model
+
+import "testing"
+
+func TestPolicyEnforce(t *testing.T) {
+	policy, err := ParsePolicyStr(policyFile)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	config, _ := ParseConfigStr(configWeak)
+	config.Enforce(policy, "octocat")
+
+	if got, want := config.Approvals, 3; got != want {
+		t.Errorf("Wanted approvals %d, got %d", want, got)
+	}
+	if got, want := config.Branches["release/*"].Approvals, 3; got != want {
+		t.Errorf("Wanted branch approvals %d, got %d", want, got)
+	}
+	if got, want := config.Rules[0].Approvals, 3; got != want {
+		t.Errorf("Wanted rule approvals %d, got %d", want, got)
+	}
+	if !config.SelfApprovalOff {
+		t.Errorf("Wanted self approval turned off")
+	}
+	if got, want := len(config.Overrides), 5; got != want {
+		t.Errorf("Wanted %d overrides, got %d", want, got)
+	}
+
+	// the org quotas added by the policy apply in addition to the
+	// approvals from any maintainer.
+	rules := config.defaultRules()
+	if got, want := len(rules), 2; got != want {
+		t.Errorf("Wanted %d default rules, got %d", want, got)
+		return
+	}
+	if got, want := rules[0].Approvals, 3; got != want {
+		t.Errorf("Wanted default approvals %d, got %d", want, got)
+	}
+	if got, want := rules[1].Team, "security"; got != want {
+		t.Errorf("Wanted quota from %s, got %s", want, got)
+	}
+}
+
+func TestPolicyWeight(t *testing.T) {
+	policy, _ := ParsePolicyStr("approvals = 2\n")
+	config, _ := ParseConfigStr("approvals = 2\n")
+	lead := &Person{Login: "bradrydzewski", Weight: 100}
+
+	if got, want := config.Granted([]*Person{lead}), 100; got != want {
+		t.Errorf("Wanted %d approvals without a policy, got %d", want, got)
+	}
+	config.Enforce(policy, "octocat")
+	if got, want := config.Granted([]*Person{lead}), 1; got != want {
+		t.Errorf("Wanted %d approval from one heavy approver, got %d", want, got)
+	}
+	if got, want := config.Granted([]*Person{lead, {Login: "mattnorris"}}), 101; got != want {
+		t.Errorf("Wanted %d approvals from two approvers, got %d", want, got)
+	}
+}
+
+func TestPolicyOwner(t *testing.T) {
+	policy, _ := ParsePolicyStr(policyFile)
+	config, _ := ParseConfigStr("approvals = 2\nself_approval_off = true\n")
+	config.Enforce(policy, "github")
+
+	if got, want := config.Approvals, 2; got != want {
+		t.Errorf("Wanted approvals %d, got %d", want, got)
+	}
+	if got, want := len(config.Overrides), 1; got != want {
+		t.Errorf("Wanted %d overrides, got %d", want, got)
+	}
+
+	_, err := ParsePolicyStr("[owner.octocat.owner.github]\napprovals = 2\n")
+	if err == nil {
+		t.Errorf("Wanted error for nested owner sections")
+	}
+}
+
+var policyFile = `
+approvals = 2
+self_approval_off = true
+
+[orgs]
+security = 1
+
+[owner.octocat]
+approvals = 3
+`
+
+var configWeak = `
+approvals = 1
+
+[branch."release/*"]
+approvals = 1
+
+[[rule]]
+name = "docs"
+paths = ["docs/**"]
+approvals = 1
+`
@@ -0,0 +1,23 @@
+package model
+
+import "strings"
+
+// JoinRemote returns the name qualified with the name of the remote it
+// belongs to, used to identify users and repositories in tokens. Names
+// belonging to the default remote are not qualified.
+func JoinRemote(remote, name string) string {
+	if len(remote) == 0 {
+		return name
+	}
+	return remote + ":" + name
+}
+
+// SplitRemote splits a qualified name into the name of the remote
+// and the name. Unqualified names belong to the default remote.
+func SplitRemote(qualified string) (remote, name string) {
+	i := strings.Index(qualified, ":")
+	if i == -1 {
+		return "", qualified
+	}
+	return qualified[:i], qualified[i+1:]
+}
@@ -0,0 +1,25 @@
+package model
+
+import "testing"
+
+func TestRemoteName(t *testing.T) {
+	var tests = []struct {
+		remote string
+		name   string
+		joined string
+	}{
+		{"", "octocat/hello-world", "octocat/hello-world"},
+		{"enterprise", "octocat/hello-world", "enterprise:octocat/hello-world"},
+		{"enterprise", "octocat", "enterprise:octocat"},
+	}
+	for _, test := range tests {
+		joined := JoinRemote(test.remote, test.name)
+		if joined != test.joined {
+			t.Errorf("Wanted %s, got %s", test.joined, joined)
+		}
+		remote, name := SplitRemote(joined)
+		if remote != test.remote || name != test.name {
+			t.Errorf("Wanted %s and %s, got %s and %s", test.remote, test.name, remote, name)
+		}
+	}
+}
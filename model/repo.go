@@ -9,6 +9,12 @@ type Repo struct {
 	Link    string `json:"link_url"           meddler:"repo_link"`
 	Private bool   `json:"private"            meddler:"repo_private"`
 	Secret  string `json:"-"                  meddler:"repo_secret"`
+	Remote  string `json:"remote"             meddler:"repo_remote"`
+
+	// Signed is true when the webhook was created with the repository
+	// secret, and false for repositories activated before hook payloads
+	// were signed, until the repository is activated again.
+	Signed bool `json:"signed" meddler:"repo_signed"`
 }
 
 type Perm struct {
@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+const (
+	ReviewApproved         = "APPROVED"
+	ReviewChangesRequested = "CHANGES_REQUESTED"
+	ReviewCommented        = "COMMENTED"
+	ReviewDismissed        = "DISMISSED"
+)
+
+// Review represents a pull request review.
+type Review struct {
+	Author  string
+	State   string
+	Created time.Time
+}
@@ -0,0 +1,143 @@
+package model
+
+import (
+	"path"
+	"sort"
+	"strings"
+)
+
+// Rule represents a path-based approval rule in the .lgtm file. A rule
+// applies to pull requests that change at least one file matching its
+// path patterns, and requires approvals from the members of the named
+// org section of the MAINTAINERS file.
+type Rule struct {
+	Name      string   `json:"name"              toml:"name"      yaml:"name"`
+	Paths     []string `json:"paths"             toml:"paths"     yaml:"paths"`
+	Approvals int      `json:"approvals"         toml:"approvals" yaml:"approvals"`
+	Team      string   `json:"team,omitempty"    toml:"team"      yaml:"team"`
+	Context   string   `json:"context,omitempty" toml:"context"   yaml:"context"`
+
+	quota bool
+	guard bool
+}
+
+// IsQuota returns true if the rule is the approval quota
+// of an org section of the MAINTAINERS file.
+func (r *Rule) IsQuota() bool {
+	return r.quota
+}
+
+// IsGuard returns true if the rule guards the policy files,
+// which the author of the pull request cannot approve.
+func (r *Rule) IsGuard() bool {
+	return r.guard
+}
+
+// IsMatch returns true if the file path matches one of the
+// rule path patterns.
+func (r *Rule) IsMatch(file string) bool {
+	for _, pattern := range r.Paths {
+		if matchPath(pattern, file) {
+			return true
+		}
+	}
+	return false
+}
+
+// Match returns the rules that apply to the list of changed files. The
+// default rules, built from the top level approval settings, apply when
+// a file is not matched by any rule.
+func (c *Config) Match(files []string) []*Rule {
+	var rules []*Rule
+	matched := map[string]bool{}
+	for _, rule := range c.Rules {
+		var ok bool
+		for _, file := range files {
+			if rule.IsMatch(file) {
+				matched[file] = true
+				ok = true
+			}
+		}
+		if ok {
+			rules = append(rules, rule)
+		}
+	}
+	if len(rules) == 0 || len(matched) != len(files) {
+		rules = append(c.defaultRules(), rules...)
+	}
+	// the guard applies in addition to the other rules, so the
+	// policy files it matches still require the usual approvals.
+	if guard := c.guardRule(); guard != nil {
+		for _, file := range files {
+			if guard.IsMatch(file) {
+				rules = append(rules, guard)
+				break
+			}
+		}
+	}
+	return rules
+}
+
+// defaultRules is a helper function that returns the rules described
+// by the top level approval settings, which are either the quota of
+// each org, or the number of approvals from any maintainer.
+func (c *Config) defaultRules() []*Rule {
+	var rules []*Rule
+	if len(c.Orgs) == 0 || c.floor {
+		rules = append(rules, &Rule{
+			Name:      "default",
+			Paths:     []string{"**"},
+			Approvals: c.Approvals,
+			Team:      c.team,
+		})
+	}
+	var orgs []string
+	for org := range c.Orgs {
+		orgs = append(orgs, org)
+	}
+	sort.Strings(orgs)
+
+	for _, org := range orgs {
+		rules = append(rules, &Rule{
+			Name:      org,
+			Paths:     []string{"**"},
+			Approvals: c.Orgs[org],
+			Team:      org,
+			quota:     true,
+		})
+	}
+	return rules
+}
+
+// matchPath is a helper function that reports whether the slash
+// separated file path matches the pattern. The pattern uses the
+// path.Match syntax, extended so that a ** segment matches zero
+// or more directories.
+func matchPath(pattern, file string) bool {
+	return matchSegments(
+		strings.Split(strings.Trim(pattern, "/"), "/"),
+		strings.Split(strings.Trim(file, "/"), "/"),
+	)
+}
+
+func matchSegments(pattern, file []string) bool {
+	for len(pattern) != 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(file); i++ {
+				if matchSegments(pattern[1:], file[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(file) == 0 {
+			return false
+		}
+		ok, err := path.Match(pattern[0], file[0])
+		if err != nil || !ok {
+			return false
+		}
+		pattern, file = pattern[1:], file[1:]
+	}
+	return len(file) == 0
+}
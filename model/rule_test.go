@@ -0,0 +1,114 @@
+package model
+
+import "testing"
+
+func TestRuleMatch(t *testing.T) {
+	var tests = []struct {
+		pattern string
+		file    string
+		match   bool
+	}{
+		{"docs/**", "docs/index.md", true},
+		{"docs/**", "docs/api/index.md", true},
+		{"docs/**", "README.md", false},
+		{"pkg/crypto/**", "pkg/crypto/aes/aes.go", true},
+		{"pkg/crypto/**", "pkg/cryptography/aes.go", false},
+		{"**/*.md", "README.md", true},
+		{"**/*.md", "docs/api/index.md", true},
+		{"*.go", "main.go", true},
+		{"*.go", "cmd/main.go", false},
+		{"**", "cmd/main.go", true},
+	}
+	for _, test := range tests {
+		rule := &Rule{Paths: []string{test.pattern}}
+		if got := rule.IsMatch(test.file); got != test.match {
+			t.Errorf("Wanted %s match %s to be %v, got %v", test.pattern, test.file, test.match, got)
+		}
+	}
+}
+
+func TestConfigRules(t *testing.T) {
+	config, err := ParseConfigStr(configRules)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(config.Rules) != 2 {
+		t.Errorf("Wanted 2 rules, got %d", len(config.Rules))
+		return
+	}
+	if config.Rules[1].Approvals != config.Approvals {
+		t.Errorf("Wanted rule approvals %d, got %d", config.Approvals, config.Rules[1].Approvals)
+	}
+	if config.Rules[1].Name != "rule2" {
+		t.Errorf("Wanted rule name rule2, got %s", config.Rules[1].Name)
+	}
+
+	var tests = []struct {
+		files []string
+		rules []string
+	}{
+		{[]string{"docs/index.md"}, []string{"docs"}},
+		{[]string{"docs/index.md", "pkg/crypto/aes.go"}, []string{"docs", "rule2"}},
+		{[]string{"docs/index.md", "main.go"}, []string{"default", "docs"}},
+		{[]string{"main.go"}, []string{"default"}},
+		{nil, []string{"default"}},
+	}
+	for _, test := range tests {
+		rules := config.Match(test.files)
+		if len(rules) != len(test.rules) {
+			t.Errorf("Wanted %d rules for %v, got %d", len(test.rules), test.files, len(rules))
+			continue
+		}
+		for i, rule := range rules {
+			if rule.Name != test.rules[i] {
+				t.Errorf("Wanted rule %s, got %s", test.rules[i], rule.Name)
+			}
+		}
+	}
+}
+
+var configRules = `
+approvals = 2
+
+[[rule]]
+name = "docs"
+paths = ["docs/**"]
+approvals = 1
+team = "docs"
+context = "approvals/docs"
+
+[[rule]]
+paths = ["pkg/crypto/**"]
+team = "security"
+`
+
+func TestConfigOrgs(t *testing.T) {
+	config, err := ParseConfigStr(configOrgs)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	rules := config.Match(nil)
+	if len(rules) != 2 {
+		t.Errorf("Wanted 2 rules, got %d", len(rules))
+		return
+	}
+	if rules[0].Team != "core" || rules[0].Approvals != 2 || !rules[0].IsQuota() {
+		t.Errorf("Wanted quota of 2 approvals from core, got %d from %s", rules[0].Approvals, rules[0].Team)
+	}
+	if rules[1].Team != "security" || rules[1].Approvals != 1 || !rules[1].IsQuota() {
+		t.Errorf("Wanted quota of 1 approval from security, got %d from %s", rules[1].Approvals, rules[1].Team)
+	}
+
+	_, err = ParseConfigStr("[orgs]\ncore = 0\n")
+	if err == nil {
+		t.Errorf("Wanted error for quota without approvals")
+	}
+}
+
+var configOrgs = `
+[orgs]
+security = 1
+core = 2
+`
@@ -0,0 +1,97 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Status represents the approval status of a pull request.
+type Status struct {
+	Granted  int      `json:"granted"`
+	Required int      `json:"required"`
+	Blockers []string `json:"blockers,omitempty"`
+
+	// Vetoes lists the maintainers blocking the pull request with a
+	// comment matching the block pattern, which fails the status.
+	Vetoes []string `json:"vetoes,omitempty"`
+
+	// Approvers and Pending list the maintainers that approved the
+	// pull request, and the eligible maintainers that have not yet
+	// responded.
+	Approvers []string `json:"approvers,omitempty"`
+	Pending   []string `json:"pending,omitempty"`
+
+	// Rule describes the approval rule applied to the pull request,
+	// and Link is the url of the repository in LGTM.
+	Rule string `json:"rule,omitempty"`
+	Link string `json:"link,omitempty"`
+
+	// Quotas lists the approvals granted and required from each
+	// org when the .lgtm file sets per-org quotas.
+	Quotas []*Quota `json:"quotas,omitempty"`
+
+	// Overrides lists the settings of the .lgtm file raised by
+	// the minimum policy enforced by the server.
+	Overrides []string `json:"overrides,omitempty"`
+
+	// WorkInProgress is true when the pull request is a draft or its
+	// title matches the wip pattern, which keeps the status pending
+	// however many approvals are granted.
+	WorkInProgress bool `json:"work_in_progress,omitempty"`
+
+	// WindowEnds is the time the review window of the pull request
+	// ends, which keeps the status pending while the window is open.
+	// It is zero when the window has ended, or there is no window.
+	WindowEnds time.Time `json:"window_ends"`
+
+	// Context overrides the name of the status reported to the
+	// remote system, used by rules that report their own status.
+	Context string `json:"context,omitempty"`
+}
+
+// Quota represents the approvals granted and required from an org.
+type Quota struct {
+	Org      string `json:"org"`
+	Granted  int    `json:"granted"`
+	Required int    `json:"required"`
+}
+
+// Summary returns a short description of the approvals granted,
+// summarizing the progress of each org when quotas are set, and
+// noting when the server policy raised the requirements.
+func (s *Status) Summary() string {
+	var summary string
+	if len(s.Quotas) == 0 {
+		summary = fmt.Sprintf("%d of %d required approvals granted", s.Granted, s.Required)
+	} else {
+		var parts []string
+		for _, quota := range s.Quotas {
+			parts = append(parts, fmt.Sprintf("%s %d/%d", quota.Org, quota.Granted, quota.Required))
+		}
+		summary = strings.Join(parts, ", ")
+	}
+	if len(s.Overrides) != 0 {
+		summary += " (raised by server policy)"
+	}
+	return summary
+}
+
+// IsApproved returns true if the required number of approvals
+// are granted, no maintainer is blocking the pull request, the
+// pull request is not a work in progress, and the review window
+// has ended.
+func (s *Status) IsApproved() bool {
+	return s.Granted >= s.Required && len(s.Blockers) == 0 && !s.IsVetoed() && !s.WorkInProgress && s.WindowEnds.IsZero()
+}
+
+// WindowSummary returns a short description of the open review
+// window of a pull request with the required approvals.
+func (s *Status) WindowSummary() string {
+	return fmt.Sprintf("approvals met, review window ends at %s", s.WindowEnds.UTC().Format("2006-01-02 15:04 MST"))
+}
+
+// IsVetoed returns true if a maintainer vetoed the pull request.
+func (s *Status) IsVetoed() bool {
+	return len(s.Vetoes) != 0
+}
@@ -0,0 +1,56 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStatusSummary(t *testing.T) {
+	status := &Status{Granted: 1, Required: 2}
+	if got := status.Summary(); got != "1 of 2 required approvals granted" {
+		t.Errorf("Wanted count summary, got %s", got)
+	}
+
+	status.Quotas = []*Quota{
+		{Org: "core", Granted: 2, Required: 2},
+		{Org: "security", Granted: 0, Required: 1},
+	}
+	if got := status.Summary(); got != "core 2/2, security 0/1" {
+		t.Errorf("Wanted quota summary, got %s", got)
+	}
+
+	status.Overrides = []string{"approvals raised from 1 to 2"}
+	if got := status.Summary(); got != "core 2/2, security 0/1 (raised by server policy)" {
+		t.Errorf("Wanted policy summary, got %s", got)
+	}
+}
+
+func TestStatusApproved(t *testing.T) {
+	status := &Status{Granted: 2, Required: 2}
+	if !status.IsApproved() {
+		t.Errorf("Wanted approved status")
+	}
+	status.Vetoes = []string{"bradrydzewski"}
+	if status.IsApproved() {
+		t.Errorf("Wanted vetoed status not approved")
+	}
+	status.Vetoes = nil
+	status.WorkInProgress = true
+	if status.IsApproved() {
+		t.Errorf("Wanted work in progress status not approved")
+	}
+}
+
+func TestStatusWindow(t *testing.T) {
+	status := &Status{
+		Granted:    2,
+		Required:   2,
+		WindowEnds: time.Date(2016, 5, 1, 12, 30, 0, 0, time.UTC),
+	}
+	if status.IsApproved() {
+		t.Errorf("Wanted status not approved during the review window")
+	}
+	if got, want := status.WindowSummary(), "approvals met, review window ends at 2016-05-01 12:30 UTC"; got != want {
+		t.Errorf("Wanted window summary %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,229 @@
+package model
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Problem kinds reported when validating the .lgtm and MAINTAINERS files.
+const (
+	ProblemSyntax     = "syntax"
+	ProblemUnknownKey = "unknown_key"
+	ProblemPattern    = "invalid_pattern"
+	ProblemValue      = "invalid_value"
+	ProblemLine       = "invalid_line"
+	ProblemLogin      = "unknown_login"
+	ProblemOrg        = "undefined_org"
+)
+
+// Problem represents a problem found when validating the .lgtm
+// or MAINTAINERS file.
+type Problem struct {
+	File    string `json:"file"`
+	Line    int    `json:"line,omitempty"`
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+}
+
+// ValidateConfig validates a projects .lgtm file in the TOML, YAML or
+// JSON format, and returns the parsed configuration along with the
+// problems found. Keys that are not recognized are reported, since
+// they are otherwise silently ignored in favor of the defaults.
+func ValidateConfig(data []byte) (*Config, []*Problem) {
+	return ValidateConfigFile(".lgtm", data)
+}
+
+// ValidateConfigFile validates a projects .lgtm file in the format of
+// the file name extension, reporting the problems found in the named
+// file.
+func ValidateConfigFile(name string, data []byte) (*Config, []*Problem) {
+	var problems []*Problem
+	report := func(kind, format string, a ...interface{}) {
+		problems = append(problems, &Problem{
+			File:    name,
+			Kind:    kind,
+			Message: fmt.Sprintf(format, a...),
+		})
+	}
+
+	c := new(Config)
+	keys, err := decodeStrict(FormatOf(name, data), data, c)
+	if err != nil {
+		report(ProblemSyntax, "%s", err)
+		return nil, problems
+	}
+	for _, key := range keys {
+		report(ProblemUnknownKey, "Unknown key %s", key)
+	}
+	unknown := len(problems)
+
+	patterns := []struct {
+		key, pattern string
+	}{
+		{"pattern", c.Pattern},
+		{"block_pattern", c.BlockPattern},
+		{"lift_pattern", c.LiftPattern},
+		{"retract_pattern", c.RetractPattern},
+		{"wip_pattern", c.WipPattern},
+	}
+	for _, p := range patterns {
+		if _, err := regexp.Compile(p.pattern); err != nil {
+			report(ProblemPattern, "Invalid %s %s. %s", p.key, p.pattern, err)
+		}
+	}
+	for _, pattern := range c.BranchPatterns() {
+		if _, err := path.Match(pattern, ""); err != nil {
+			report(ProblemPattern, "Invalid branch pattern %s. %s", pattern, err)
+		}
+	}
+	for i, rule := range c.Rules {
+		for _, pattern := range rule.Paths {
+			for _, segment := range strings.Split(pattern, "/") {
+				if _, err := path.Match(segment, ""); err != nil {
+					report(ProblemPattern, "Invalid path %s in rule %d. %s", pattern, i+1, err)
+					break
+				}
+			}
+		}
+	}
+	if len(problems) != unknown {
+		return nil, problems
+	}
+
+	// the remaining settings are validated when the file is parsed.
+	config, err := ParseConfigFile(name, data)
+	if err != nil {
+		report(ProblemValue, "%s", err)
+		return nil, problems
+	}
+	return config, problems
+}
+
+// ValidateMaintainer validates a projects MAINTAINERS file, and returns
+// the parsed maintainers along with the problems found. Lines of a text
+// file that cannot be parsed are reported individually.
+func ValidateMaintainer(data []byte) (*Maintainer, []*Problem) {
+	return ValidateMaintainerFile("MAINTAINERS", data)
+}
+
+// ValidateMaintainerFile validates a projects MAINTAINERS file in the
+// format of the file name extension, reporting the problems found in
+// the named file.
+func ValidateMaintainerFile(name string, data []byte) (*Maintainer, []*Problem) {
+	var problems []*Problem
+	report := func(line int, kind, format string, a ...interface{}) {
+		problems = append(problems, &Problem{
+			File:    name,
+			Line:    line,
+			Kind:    kind,
+			Message: fmt.Sprintf(format, a...),
+		})
+	}
+
+	format := FormatOf(name, data)
+	m := new(Maintainer)
+	keys, err := decodeStrict(format, data, m)
+	switch {
+	case err == nil && m.People != nil:
+		for _, key := range keys {
+			report(0, ProblemUnknownKey, "Unknown key %s", key)
+		}
+		for _, login := range sortedPeople(m) {
+			if m.People[login].Weight < 0 {
+				report(0, ProblemValue, "Invalid weight for %s. Weight must not be negative.", login)
+			}
+		}
+		maintainer, err := ParseMaintainerFile(name, data)
+		if err != nil {
+			return nil, problems
+		}
+		return maintainer, problems
+	case err != nil && (format == FormatJSON || bytes.Contains(data, []byte("[people"))):
+		report(0, ProblemSyntax, "%s", err)
+		return nil, problems
+	}
+
+	m = new(Maintainer)
+	m.People = map[string]*Person{}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for i := 1; scanner.Scan(); i++ {
+		item := parseln(scanner.Text())
+		if len(item) == 0 {
+			continue
+		}
+		person := parseLogin(item)
+		if person == nil {
+			person = parseLoginMeta(item)
+		}
+		if person == nil {
+			person = parseLoginEmail(item)
+		}
+		if person == nil {
+			report(i, ProblemLine, "Invalid line %q", item)
+			continue
+		}
+		m.People[person.Login] = person
+	}
+	return m, problems
+}
+
+// ValidateOrgs returns a problem for each org referenced by the rules,
+// guard or quotas of the .lgtm file that is not defined in the
+// MAINTAINERS file.
+func ValidateOrgs(c *Config, m *Maintainer) []*Problem {
+	file := ".lgtm"
+	if len(c.Path) != 0 {
+		file = c.Path
+	}
+	var problems []*Problem
+	report := func(org, format string, a ...interface{}) {
+		if _, ok := m.Org[org]; ok {
+			return
+		}
+		problems = append(problems, &Problem{
+			File:    file,
+			Kind:    ProblemOrg,
+			Message: fmt.Sprintf(format, a...),
+		})
+	}
+
+	for _, rule := range c.Rules {
+		if len(rule.Team) != 0 {
+			report(rule.Team, "Org %s of rule %s is not defined in the MAINTAINERS file", rule.Team, rule.Name)
+		}
+	}
+	if c.Guard != nil && len(c.Guard.Team) != 0 {
+		report(c.Guard.Team, "Org %s of the guard is not defined in the MAINTAINERS file", c.Guard.Team)
+	}
+	for _, class := range c.authorClasses() {
+		if team := c.Authors[class].Team; len(team) != 0 {
+			report(team, "Org %s of the %s author policy is not defined in the MAINTAINERS file", team, class)
+		}
+	}
+	var orgs []string
+	for org := range c.Orgs {
+		orgs = append(orgs, org)
+	}
+	sort.Strings(orgs)
+	for _, org := range orgs {
+		report(org, "Org %s of the approval quotas is not defined in the MAINTAINERS file", org)
+	}
+	return problems
+}
+
+// sortedPeople is a helper function that returns the sorted
+// logins of the people in the MAINTAINERS file.
+func sortedPeople(m *Maintainer) []string {
+	var logins []string
+	for login := range m.People {
+		logins = append(logins, login)
+	}
+	sort.Strings(logins)
+	return logins
+}
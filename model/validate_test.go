@@ -0,0 +1,89 @@
+package model
+
+import "testing"
+
+func TestValidateConfig(t *testing.T) {
+	config, problems := ValidateConfig([]byte(configTypo))
+	if config == nil {
+		t.Errorf("Wanted config parsed despite unknown keys")
+	}
+	if got, want := len(problems), 2; got != want {
+		t.Errorf("Wanted %d problems, got %d", want, got)
+		return
+	}
+	for _, problem := range problems {
+		if problem.Kind != ProblemUnknownKey {
+			t.Errorf("Wanted unknown key, got %s", problem.Kind)
+		}
+	}
+
+	config, problems = ValidateConfig([]byte("block_pattern = \"(\"\n[branch.\"release/[\"]\n"))
+	if config != nil {
+		t.Errorf("Wanted no config for invalid patterns")
+	}
+	if got, want := len(problems), 2; got != want {
+		t.Errorf("Wanted %d problems, got %d", want, got)
+		return
+	}
+	if got, want := problems[0].Kind, ProblemPattern; got != want {
+		t.Errorf("Wanted problem %s, got %s", want, got)
+	}
+
+	_, problems = ValidateConfig([]byte("approvals = \n"))
+	if len(problems) != 1 || problems[0].Kind != ProblemSyntax {
+		t.Errorf("Wanted syntax problem, got %v", problems)
+	}
+}
+
+func TestValidateMaintainer(t *testing.T) {
+	maintainer, problems := ValidateMaintainer([]byte(maintainerFileInvalid))
+	if got, want := len(maintainer.People), 2; got != want {
+		t.Errorf("Wanted %d people, got %d", want, got)
+	}
+	if got, want := len(problems), 1; got != want {
+		t.Errorf("Wanted %d problems, got %d", want, got)
+		return
+	}
+	if got, want := problems[0].Line, 3; got != want {
+		t.Errorf("Wanted problem on line %d, got %d", want, got)
+	}
+	if got, want := problems[0].Kind, ProblemLine; got != want {
+		t.Errorf("Wanted problem %s, got %s", want, got)
+	}
+}
+
+func TestValidateOrgs(t *testing.T) {
+	config, _ := ParseConfigStr("[orgs]\ncore = 1\nsecurity = 1\n")
+	maintainer, _ := ParseMaintainerStr(maintainerFileOrg)
+	problems := ValidateOrgs(config, maintainer)
+	if got, want := len(problems), 1; got != want {
+		t.Errorf("Wanted %d problems, got %d", want, got)
+		return
+	}
+	if got, want := problems[0].Kind, ProblemOrg; got != want {
+		t.Errorf("Wanted problem %s, got %s", want, got)
+	}
+}
+
+var maintainerFileOrg = `
+[people]
+
+	[people.bradrydzewski]
+	login = "bradrydzewski"
+
+[org]
+
+	[org.core]
+	people = ["bradrydzewski"]
+`
+
+var configTypo = `
+aprovals = 3
+self_aproval_off = true
+`
+
+var maintainerFileInvalid = `
+bradrydzewski
+Brad Rydzewski brad.rydzewski@mail.com
+Matt Norris <matt.norris@mail.com> (@mattnorris)
+`
@@ -0,0 +1,27 @@
+package policy
+
+import (
+	"github.com/lgtmco/lgtm/model"
+
+	"golang.org/x/net/context"
+)
+
+const key = "policy"
+
+// Setter defines a context that enables setting values.
+type Setter interface {
+	Set(string, interface{})
+}
+
+// FromContext returns the Policy associated with this context,
+// or nil if the server does not enforce a policy.
+func FromContext(c context.Context) *model.Policy {
+	p, _ := c.Value(key).(*model.Policy)
+	return p
+}
+
+// ToContext adds the Policy to this context if it supports
+// the Setter interface.
+func ToContext(c Setter, p *model.Policy) {
+	c.Set(key, p)
+}
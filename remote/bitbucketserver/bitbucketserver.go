@@ -0,0 +1,526 @@
+package bitbucketserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/lgtmco/lgtm/model"
+	"github.com/lgtmco/lgtm/shared/httputil"
+	"golang.org/x/oauth2"
+)
+
+// name of the build status key posted to Bitbucket
+const context = "approvals/lgtm"
+
+// Bitbucket Server identifies repositories by project key and repository
+// slug, which are mapped to the repository owner and name respectively.
+type Bitbucket struct {
+	URL    string
+	Client string
+	Secret string
+	Scopes []string
+}
+
+func (b *Bitbucket) GetUser(res http.ResponseWriter, req *http.Request) (*model.User, error) {
+
+	var config = &oauth2.Config{
+		ClientID:     b.Client,
+		ClientSecret: b.Secret,
+		Scopes:       b.Scopes,
+		RedirectURL:  fmt.Sprintf("%s/login", httputil.GetURL(req)),
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  fmt.Sprintf("%s/rest/oauth2/latest/authorize", b.URL),
+			TokenURL: fmt.Sprintf("%s/rest/oauth2/latest/token", b.URL),
+		},
+	}
+
+	// get the oauth code from the incoming request. if no code is present
+	// redirec the user to Bitbucket login to retrieve a code.
+	var code = req.FormValue("code")
+	if len(code) == 0 {
+		state := fmt.Sprintln(time.Now().Unix())
+		http.Redirect(res, req, config.AuthCodeURL(state), http.StatusSeeOther)
+		return nil, nil
+	}
+
+	// exchanges the oauth2 code for an access token
+	token, err := config.Exchange(oauth2.NoContext, code)
+	if err != nil {
+		return nil, fmt.Errorf("Error exchanging token. %s", err)
+	}
+
+	// get the currently authenticated user details for the access token
+	client := NewClientToken(b.base(), token.AccessToken)
+	user, err := getUser(client)
+	if err != nil {
+		return nil, fmt.Errorf("Error fetching user. %s", err)
+	}
+
+	return &model.User{
+		Login:  user.Name,
+		Email:  user.Email,
+		Token:  token.AccessToken,
+		Avatar: fmt.Sprintf("%s/users/%s/avatar.png", b.URL, user.Slug),
+	}, nil
+}
+
+func (b *Bitbucket) GetUserToken(token string) (string, error) {
+	client := NewClientToken(b.base(), token)
+	user, err := getUser(client)
+	if err != nil {
+		return "", fmt.Errorf("Error fetching user. %s", err)
+	}
+	return user.Name, nil
+}
+
+func (b *Bitbucket) GetTeams(user *model.User) ([]*model.Team, error) {
+	client := NewClientToken(b.base(), user.Token)
+	projects, err := client.Projects()
+	if err != nil {
+		return nil, fmt.Errorf("Error fetching teams. %s", err)
+	}
+	teams := []*model.Team{}
+	for _, project := range projects {
+		teams = append(teams, &model.Team{
+			Login:  project.Key,
+			Avatar: fmt.Sprintf("%s/projects/%s/avatar.png", b.URL, project.Key),
+		})
+	}
+	return teams, nil
+}
+
+func (b *Bitbucket) GetMember(user *model.User, login string) (*model.Member, error) {
+	client := NewClientToken(b.base(), user.Token)
+	account, err := client.User(login)
+	if err != nil {
+		return nil, err
+	}
+	return &model.Member{
+		Login: account.Slug,
+	}, nil
+}
+
+// GetMembers returns the users granted write or admin permission on
+// the project, which are allowed to merge pull requests.
+func (b *Bitbucket) GetMembers(user *model.User, team string) ([]*model.Member, error) {
+	if strings.Contains(team, "/") {
+		return nil, fmt.Errorf("Error fetching team %s. Bitbucket Server does not support teams.", team)
+	}
+	client := NewClientToken(b.base(), user.Token)
+	perms, err := client.ProjectPerms(team)
+	if err != nil {
+		return nil, fmt.Errorf("Error fetching project members. %s", err)
+	}
+	var members []*model.Member
+	for _, perm := range perms {
+		if perm.Permission != "PROJECT_WRITE" && perm.Permission != "PROJECT_ADMIN" {
+			continue
+		}
+		members = append(members, &model.Member{
+			Login: perm.User.Name,
+		})
+	}
+	return members, nil
+}
+
+func (b *Bitbucket) GetRepo(user *model.User, owner, name string) (*model.Repo, error) {
+	client := NewClientToken(b.base(), user.Token)
+	repo_, err := client.Repo(owner, name)
+	if err != nil {
+		return nil, fmt.Errorf("Error fetching repository. %s", err)
+	}
+	return convertRepo(repo_), nil
+}
+
+// GetPerm returns the repository permissions. Bitbucket does not report
+// the permissions of the current user on a repository, so instead the
+// repository is searched for among those granted each permission.
+func (b *Bitbucket) GetPerm(user *model.User, owner, name string) (*model.Perm, error) {
+	client := NewClientToken(b.base(), user.Token)
+	_, err := client.Repo(owner, name)
+	if err != nil {
+		return nil, fmt.Errorf("Error fetching repository. %s", err)
+	}
+	m := &model.Perm{Pull: true}
+	m.Push, err = client.RepoPerm(owner, name, "REPO_WRITE")
+	if err != nil {
+		return nil, fmt.Errorf("Error fetching repository permissions. %s", err)
+	}
+	m.Admin, err = client.RepoPerm(owner, name, "REPO_ADMIN")
+	if err != nil {
+		return nil, fmt.Errorf("Error fetching repository permissions. %s", err)
+	}
+	return m, nil
+}
+
+func (b *Bitbucket) GetRepos(u *model.User) ([]*model.Repo, error) {
+	client := NewClientToken(b.base(), u.Token)
+
+	// only list repositories that I can admin
+	all, err := client.Repos("REPO_ADMIN")
+	if err != nil {
+		return nil, err
+	}
+	repos := []*model.Repo{}
+	for _, repo := range all {
+		repos = append(repos, convertRepo(repo))
+	}
+	return repos, nil
+}
+
+func (b *Bitbucket) SetHook(user *model.User, repo *model.Repo, link string) error {
+	client := NewClientToken(b.base(), user.Token)
+
+	old, err := getHook(client, repo.Owner, repo.Name, link)
+	if err == nil && old != nil {
+		client.HookDelete(repo.Owner, repo.Name, old.ID)
+	}
+
+	err = client.HookCreate(repo.Owner, repo.Name, &Webhook{
+		Name:   "lgtm",
+		URL:    link,
+		Active: true,
+		Events: []string{
+			"pr:comment:added",
+			"pr:from_ref_updated",
+			"pr:modified",
+			"pr:reviewer:approved",
+			"pr:reviewer:unapproved",
+			"pr:reviewer:needs_work",
+		},
+		Configuration: map[string]string{
+			"secret": repo.Secret,
+		},
+	})
+	if err != nil {
+		log.Debugf("Error creating the webhook at %s. %s", link, err)
+		return err
+	}
+
+	branch, err := client.DefaultBranch(repo.Owner, repo.Name)
+	if err != nil {
+		log.Warnf("Error fetching default branch for %s. %s", repo.Slug, err)
+		return nil
+	}
+
+	// restrict the default branch to changes made through pull requests,
+	// and require the approval status before a pull request is merged.
+	err = client.RestrictionCreate(repo.Owner, repo.Name, &Restriction{
+		Type:    "pull-request-only",
+		Matcher: branchMatcher(branch.ID),
+	})
+	if err != nil {
+		log.Warnf("Error restricting branch %s for %s. %s", branch.DisplayID, repo.Slug, err)
+	}
+	err = setCondition(client, repo.Owner, repo.Name, branchMatcher(branch.ID))
+	if err != nil {
+		log.Warnf("Error requiring approval status for %s. %s", repo.Slug, err)
+	}
+	return nil
+}
+
+// SetBranches restricts the branches matching the patterns to changes
+// made through pull requests, and requires the approval status before
+// merging. Bitbucket matches patterns when a pull request is merged, so
+// they also apply to branches created later.
+func (b *Bitbucket) SetBranches(user *model.User, repo *model.Repo, patterns []string) error {
+	client := NewClientToken(b.base(), user.Token)
+
+	for _, pattern := range patterns {
+		err := client.RestrictionCreate(repo.Owner, repo.Name, &Restriction{
+			Type:    "pull-request-only",
+			Matcher: patternMatcher(pattern),
+		})
+		if err != nil {
+			log.Warnf("Error restricting branch %s for %s. %s", pattern, repo.Slug, err)
+		}
+		err = setCondition(client, repo.Owner, repo.Name, patternMatcher(pattern))
+		if err != nil {
+			return fmt.Errorf("Error requiring approval status for %s. %s", pattern, err)
+		}
+	}
+	return nil
+}
+
+func (b *Bitbucket) DelHook(user *model.User, repo *model.Repo, link string) error {
+	client := NewClientToken(b.base(), user.Token)
+
+	hook, err := getHook(client, repo.Owner, repo.Name, link)
+	if err != nil {
+		return err
+	} else if hook == nil {
+		return nil
+	}
+	err = client.HookDelete(repo.Owner, repo.Name, hook.ID)
+	if err != nil {
+		return err
+	}
+
+	conditions, err := getConditions(client, repo.Owner, repo.Name)
+	if err != nil {
+		return nil
+	}
+	for _, condition := range conditions {
+		err = client.ConditionDelete(repo.Owner, repo.Name, condition.ID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetComments returns the pull request comments from the pull request
+// activity, which Bitbucket lists newest first.
+func (b *Bitbucket) GetComments(u *model.User, r *model.Repo, num int) ([]*model.Comment, error) {
+	client := NewClientToken(b.base(), u.Token)
+
+	activities, err := client.Activities(r.Owner, r.Name, num)
+	if err != nil {
+		return nil, err
+	}
+	comments := []*model.Comment{}
+	for _, activity := range activities {
+		if activity.Action != "COMMENTED" || activity.CommentAction != "ADDED" || activity.Comment == nil {
+			continue
+		}
+		comments = append(comments, &model.Comment{
+			Author:  activity.Comment.Author.Name,
+			Body:    activity.Comment.Text,
+			Created: toTime(activity.Comment.Created),
+		})
+	}
+	return comments, nil
+}
+
+// GetReviews returns the reviewer approvals from the pull request activity.
+// Bitbucket lists activity newest first, which is reversed to return the
+// reviews in chronological order.
+func (b *Bitbucket) GetReviews(u *model.User, r *model.Repo, num int) ([]*model.Review, error) {
+	client := NewClientToken(b.base(), u.Token)
+
+	activities, err := client.Activities(r.Owner, r.Name, num)
+	if err != nil {
+		return nil, err
+	}
+	reviews := []*model.Review{}
+	for i := len(activities) - 1; i >= 0; i-- {
+		activity := activities[i]
+
+		var state string
+		switch activity.Action {
+		case "APPROVED":
+			state = model.ReviewApproved
+		case "UNAPPROVED":
+			state = model.ReviewDismissed
+		case "REVIEWED":
+			state = model.ReviewChangesRequested
+		default:
+			continue
+		}
+		reviews = append(reviews, &model.Review{
+			Author:  activity.User.Name,
+			State:   state,
+			Created: toTime(activity.Created),
+		})
+	}
+	return reviews, nil
+}
+
+func (b *Bitbucket) GetContentsRef(u *model.User, r *model.Repo, path, ref string) ([]byte, error) {
+	client := NewClientToken(b.base(), u.Token)
+	if len(ref) == 0 {
+		return client.Raw(r.Owner, r.Name, path)
+	}
+	return client.RawAt(r.Owner, r.Name, path, ref)
+}
+
+func (b *Bitbucket) GetHead(u *model.User, r *model.Repo, num int) (*model.Commit, error) {
+	client := NewClientToken(b.base(), u.Token)
+
+	pr, err := client.PullRequest(r.Owner, r.Name, num)
+	if err != nil {
+		return nil, err
+	}
+	commit, err := client.Commit(r.Owner, r.Name, pr.FromRef.LatestCommit)
+	if err != nil {
+		return nil, err
+	}
+	return &model.Commit{
+		SHA:     commit.ID,
+		Created: toTime(commit.Committed),
+	}, nil
+}
+
+func (b *Bitbucket) GetIssue(u *model.User, r *model.Repo, num int) (*model.Issue, error) {
+	client := NewClientToken(b.base(), u.Token)
+
+	pr, err := client.PullRequest(r.Owner, r.Name, num)
+	if err != nil {
+		return nil, err
+	}
+	return &model.Issue{
+		Number:  num,
+		Title:   pr.Title,
+		Author:  pr.Author.User.Name,
+		Branch:  pr.ToRef.DisplayID,
+		Base:    pr.ToRef.LatestCommit,
+		Draft:   pr.Draft,
+		Created: toTime(pr.Created),
+	}, nil
+}
+
+// GetAuthor classifies the author as a member when the author has
+// write or admin permission on the project, like the members
+// allowed to merge pull requests.
+func (b *Bitbucket) GetAuthor(u *model.User, r *model.Repo, login string) (*model.Author, error) {
+	members, err := b.GetMembers(u, r.Owner)
+	if err != nil {
+		return nil, err
+	}
+	author := &model.Author{Login: login}
+	for _, member := range members {
+		if strings.EqualFold(member.Login, login) {
+			author.Member = true
+			break
+		}
+	}
+	client := NewClientToken(b.base(), u.Token)
+	merged, err := client.MergedBy(r.Owner, r.Name, login)
+	if err != nil {
+		return nil, fmt.Errorf("Error fetching pull requests. %s", err)
+	}
+	author.FirstTime = len(merged) == 0
+	return author, nil
+}
+
+func (b *Bitbucket) GetFiles(u *model.User, r *model.Repo, num int) ([]string, error) {
+	client := NewClientToken(b.base(), u.Token)
+
+	files := []string{}
+	for start := 0; ; {
+		changes, err := client.Changes(r.Owner, r.Name, num, start)
+		if err != nil {
+			return nil, err
+		}
+		for _, change := range changes.Values {
+			files = append(files, change.Path.ToString)
+			if change.SrcPath != nil && change.SrcPath.ToString != change.Path.ToString {
+				files = append(files, change.SrcPath.ToString)
+			}
+		}
+		if changes.IsLastPage {
+			break
+		}
+		start = changes.NextPageStart
+	}
+	return files, nil
+}
+
+func (b *Bitbucket) SetStatus(u *model.User, r *model.Repo, num int, s *model.Status) error {
+	client := NewClientToken(b.base(), u.Token)
+
+	pr, err := client.PullRequest(r.Owner, r.Name, num)
+	if err != nil {
+		return err
+	}
+
+	status := "SUCCESSFUL"
+	desc := "this commit looks good"
+
+	switch {
+	case s.WorkInProgress:
+		status = "INPROGRESS"
+		desc = "work in progress"
+	case s.IsVetoed():
+		status = "FAILED"
+		desc = fmt.Sprintf("blocked by %s", strings.Join(s.Vetoes, ", "))
+	case len(s.Blockers) != 0:
+		status = "INPROGRESS"
+		desc = fmt.Sprintf("changes requested by %s", strings.Join(s.Blockers, ", "))
+	case s.Granted < s.Required:
+		status = "INPROGRESS"
+		desc = s.Summary()
+	case !s.WindowEnds.IsZero():
+		status = "INPROGRESS"
+		desc = s.WindowSummary()
+	}
+
+	name := context
+	if len(s.Context) != 0 {
+		name = s.Context
+	}
+
+	return client.StatusCreate(pr.FromRef.LatestCommit, &Status{
+		State: status,
+		Key:   name,
+		Name:  "LGTM",
+		URL:   fmt.Sprintf("%s/projects/%s/repos/%s/pull-requests/%d", b.URL, r.Owner, r.Name, num),
+		Desc:  desc,
+	})
+}
+
+func (b *Bitbucket) GetHook(r *http.Request) (*model.Hook, error) {
+	switch r.Header.Get("X-Event-Key") {
+	case "pr:comment:added",
+		"pr:from_ref_updated",
+		"pr:modified",
+		"pr:reviewer:approved",
+		"pr:reviewer:unapproved",
+		"pr:reviewer:needs_work":
+		return parsePullRequestHook(r)
+	default:
+		return nil, nil
+	}
+}
+
+// GetAppUser returns nil, as Bitbucket Server does not support authenticating
+// as an application.
+func (b *Bitbucket) GetAppUser(r *model.Repo) (*model.User, error) {
+	return nil, nil
+}
+
+func (b *Bitbucket) SetInstallation(r *http.Request) error {
+	return nil
+}
+
+func parsePullRequestHook(r *http.Request) (*model.Hook, error) {
+	data := pullRequestHook{}
+	err := json.NewDecoder(r.Body).Decode(&data)
+	if err != nil {
+		return nil, err
+	}
+
+	repo := data.PullRequest.ToRef.Repository
+
+	hook := new(model.Hook)
+	hook.Issue = new(model.Issue)
+	hook.Issue.Number = data.PullRequest.ID
+	hook.Issue.Title = data.PullRequest.Title
+	hook.Issue.Author = data.PullRequest.Author.User.Name
+	hook.Issue.Branch = data.PullRequest.ToRef.DisplayID
+	hook.Issue.Base = data.PullRequest.ToRef.LatestCommit
+	hook.Issue.Draft = data.PullRequest.Draft
+	hook.Issue.Created = toTime(data.PullRequest.Created)
+	hook.Repo = new(model.Repo)
+	hook.Repo.Owner = repo.Project.Key
+	hook.Repo.Name = repo.Slug
+	hook.Repo.Slug = fmt.Sprintf("%s/%s", repo.Project.Key, repo.Slug)
+
+	if data.Comment != nil {
+		hook.Comment = new(model.Comment)
+		hook.Comment.Body = data.Comment.Text
+		hook.Comment.Author = data.Comment.Author.Name
+	}
+
+	return hook, nil
+}
+
+// base returns the server url with a trailing slash, to which
+// the client appends the rest api paths.
+func (b *Bitbucket) base() string {
+	return b.URL + "/"
+}
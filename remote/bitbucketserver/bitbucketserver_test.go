@@ -0,0 +1,294 @@
+package bitbucketserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lgtmco/lgtm/model"
+
+	"github.com/franela/goblin"
+)
+
+func TestBitbucket(t *testing.T) {
+
+	var server *httptest.Server
+	var requests []*http.Request
+	var payloads [][]byte
+
+	g := goblin.Goblin(t)
+	g.Describe("Bitbucket Server", func() {
+
+		var bitbucket *Bitbucket
+
+		g.Before(func() {
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := ioutil.ReadAll(r.Body)
+				requests = append(requests, r)
+				payloads = append(payloads, body)
+				fakeServer(w, r)
+			}))
+		})
+		g.After(func() {
+			server.Close()
+		})
+		g.BeforeEach(func() {
+			requests = nil
+			payloads = nil
+			bitbucket = &Bitbucket{URL: server.URL}
+		})
+
+		g.It("Should get the user for the token", func() {
+			login, err := bitbucket.GetUserToken(fakeUser.Token)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(login).Equal("octocat")
+		})
+
+		g.It("Should get the project members with write access", func() {
+			members, err := bitbucket.GetMembers(fakeUser, "OCTO")
+			g.Assert(err == nil).IsTrue()
+			g.Assert(len(members)).Equal(2)
+			g.Assert(members[0].Login).Equal("octocat")
+			g.Assert(members[1].Login).Equal("hubot")
+		})
+
+		g.It("Should classify the pull request author", func() {
+			author, err := bitbucket.GetAuthor(fakeUser, fakeRepo, "hubot")
+			g.Assert(err == nil).IsTrue()
+			g.Assert(author.Member).IsTrue()
+			g.Assert(author.FirstTime).IsFalse()
+
+			author, err = bitbucket.GetAuthor(fakeUser, fakeRepo, "spaceghost")
+			g.Assert(err == nil).IsTrue()
+			g.Assert(author.Member).IsFalse()
+			g.Assert(author.FirstTime).IsTrue()
+		})
+
+		g.It("Should get the repository", func() {
+			repo, err := bitbucket.GetRepo(fakeUser, "OCTO", "hello-world")
+			g.Assert(err == nil).IsTrue()
+			g.Assert(repo.Slug).Equal("OCTO/hello-world")
+			g.Assert(repo.Private).IsTrue()
+			g.Assert(repo.Link).Equal("http://bitbucket.example.com/projects/OCTO/repos/hello-world/browse")
+		})
+
+		g.It("Should get the repository permissions", func() {
+			perm, err := bitbucket.GetPerm(fakeUser, "OCTO", "hello-world")
+			g.Assert(err == nil).IsTrue()
+			g.Assert(perm.Pull).IsTrue()
+			g.Assert(perm.Push).IsTrue()
+			g.Assert(perm.Admin).IsFalse()
+		})
+
+		g.It("Should create the hook and restrict the branch", func() {
+			err := bitbucket.SetHook(fakeUser, fakeRepo, "http://lgtm.example.com/hook?access_token=x")
+			g.Assert(err == nil).IsTrue()
+
+			var created, restricted, required bool
+			for i, r := range requests {
+				switch {
+				case r.Method == "POST" && r.URL.Path == "/rest/api/1.0/projects/OCTO/repos/hello-world/webhooks":
+					hook := new(Webhook)
+					json.Unmarshal(payloads[i], hook)
+					g.Assert(hook.Configuration["secret"]).Equal(fakeRepo.Secret)
+					created = true
+				case r.Method == "POST" && r.URL.Path == "/rest/branch-permissions/2.0/projects/OCTO/repos/hello-world/restrictions":
+					restriction := new(Restriction)
+					json.Unmarshal(payloads[i], restriction)
+					g.Assert(restriction.Type).Equal("pull-request-only")
+					g.Assert(restriction.Matcher.ID).Equal("refs/heads/master")
+					restricted = true
+				case r.Method == "POST" && r.URL.Path == "/rest/required-builds/latest/projects/OCTO/repos/hello-world/condition":
+					condition := new(Condition)
+					json.Unmarshal(payloads[i], condition)
+					g.Assert(condition.BuildKeys).Equal([]string{"approvals/lgtm"})
+					required = true
+				}
+			}
+			g.Assert(created).IsTrue()
+			g.Assert(restricted).IsTrue()
+			g.Assert(required).IsTrue()
+		})
+
+		g.It("Should get the comments of every page newest first", func() {
+			comments, err := bitbucket.GetComments(fakeUser, fakeRepo, 1)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(len(comments)).Equal(2)
+			g.Assert(comments[0].Author).Equal("hubot")
+			g.Assert(comments[0].Body).Equal("LGTM")
+			g.Assert(comments[1].Author).Equal("octocat")
+		})
+
+		g.It("Should get the reviews in chronological order", func() {
+			reviews, err := bitbucket.GetReviews(fakeUser, fakeRepo, 1)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(len(reviews)).Equal(3)
+			g.Assert(reviews[0].State).Equal(model.ReviewApproved)
+			g.Assert(reviews[1].State).Equal(model.ReviewChangesRequested)
+			g.Assert(reviews[2].State).Equal(model.ReviewDismissed)
+			g.Assert(reviews[2].Author).Equal("hubot")
+		})
+
+		g.It("Should get the file contents", func() {
+			data, err := bitbucket.GetContentsRef(fakeUser, fakeRepo, "MAINTAINERS", "")
+			g.Assert(err == nil).IsTrue()
+			g.Assert(string(data)).Equal("hubot\n")
+		})
+
+		g.It("Should get the head commit", func() {
+			commit, err := bitbucket.GetHead(fakeUser, fakeRepo, 1)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(commit.SHA).Equal("6104942438c14ec7bd21c6cd5bd995272b3faff6")
+			g.Assert(commit.Created.Unix()).Equal(int64(1462107600))
+		})
+
+		g.It("Should set the build status", func() {
+			err := bitbucket.SetStatus(fakeUser, fakeRepo, 1, &model.Status{Granted: 1, Required: 2})
+			g.Assert(err == nil).IsTrue()
+
+			status := new(Status)
+			json.Unmarshal(payloads[len(payloads)-1], status)
+			g.Assert(status.State).Equal("INPROGRESS")
+			g.Assert(status.Key).Equal("approvals/lgtm")
+			g.Assert(status.Desc).Equal("1 of 2 required approvals granted")
+		})
+
+		g.It("Should parse a comment hook", func() {
+			req, _ := http.NewRequest("POST", "/hook", bytes.NewBufferString(fakeCommentHook))
+			req.Header.Set("X-Event-Key", "pr:comment:added")
+			hook, err := bitbucket.GetHook(req)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(hook.Repo.Slug).Equal("OCTO/hello-world")
+			g.Assert(hook.Repo.Owner).Equal("OCTO")
+			g.Assert(hook.Issue.Number).Equal(1)
+			g.Assert(hook.Issue.Author).Equal("octocat")
+			g.Assert(hook.Comment.Author).Equal("hubot")
+			g.Assert(hook.Comment.Body).Equal("LGTM")
+		})
+
+		g.It("Should parse an approval hook", func() {
+			req, _ := http.NewRequest("POST", "/hook", bytes.NewBufferString(fakePullHook))
+			req.Header.Set("X-Event-Key", "pr:reviewer:approved")
+			hook, err := bitbucket.GetHook(req)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(hook.Issue.Number).Equal(1)
+			g.Assert(hook.Comment == nil).IsTrue()
+		})
+
+		g.It("Should ignore other hooks", func() {
+			req, _ := http.NewRequest("POST", "/hook", bytes.NewBufferString("{}"))
+			req.Header.Set("X-Event-Key", "diagnostics:ping")
+			hook, err := bitbucket.GetHook(req)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(hook == nil).IsTrue()
+		})
+	})
+}
+
+// fakeServer is a stand-in for the subset of the Bitbucket Server
+// api used by the remote driver.
+func fakeServer(w http.ResponseWriter, r *http.Request) {
+	switch r.Method + " " + r.URL.Path {
+	case "GET /plugins/servlet/applinks/whoami":
+		w.Write([]byte("octocat"))
+	case "GET /rest/api/1.0/users/octocat":
+		w.Write([]byte(`{"name": "octocat", "slug": "octocat", "emailAddress": "octocat@example.com"}`))
+	case "GET /rest/api/1.0/projects/OCTO/permissions/users":
+		w.Write([]byte(`{"values": [
+			{"user": {"name": "octocat"}, "permission": "PROJECT_ADMIN"},
+			{"user": {"name": "hubot"}, "permission": "PROJECT_WRITE"},
+			{"user": {"name": "spaceghost"}, "permission": "PROJECT_READ"}
+		]}`))
+	case "GET /rest/api/1.0/projects/OCTO/repos/hello-world":
+		w.Write([]byte(fakeRepoJSON))
+	case "GET /rest/api/1.0/repos":
+		if r.FormValue("permission") == "REPO_WRITE" {
+			w.Write([]byte(`{"values": [` + fakeRepoJSON + `]}`))
+		} else {
+			w.Write([]byte(`{"values": []}`))
+		}
+	case "GET /rest/api/1.0/projects/OCTO/repos/hello-world/branches/default":
+		w.Write([]byte(`{"id": "refs/heads/master", "displayId": "master"}`))
+	case "GET /rest/api/1.0/projects/OCTO/repos/hello-world/webhooks":
+		w.Write([]byte(`{"values": []}`))
+	case "POST /rest/api/1.0/projects/OCTO/repos/hello-world/webhooks",
+		"POST /rest/branch-permissions/2.0/projects/OCTO/repos/hello-world/restrictions",
+		"POST /rest/required-builds/latest/projects/OCTO/repos/hello-world/condition":
+		w.WriteHeader(201)
+		w.Write([]byte(`{}`))
+	case "GET /rest/required-builds/latest/projects/OCTO/repos/hello-world/conditions":
+		w.Write([]byte(`{"values": []}`))
+	case "GET /rest/api/1.0/projects/OCTO/repos/hello-world/pull-requests":
+		if r.FormValue("username.1") == "hubot" {
+			w.Write([]byte(`{"values": [{"id": 2, "title": "Fix typo", "author": {"user": {"name": "hubot"}}}]}`))
+			return
+		}
+		w.Write([]byte(`{"values": []}`))
+	case "GET /rest/api/1.0/projects/OCTO/repos/hello-world/pull-requests/1/activities":
+		if r.FormValue("start") == "0" {
+			w.Write([]byte(`{"isLastPage": false, "nextPageStart": 3, "values": [
+			{"action": "UNAPPROVED", "createdDate": 1462114800000, "user": {"name": "hubot"}},
+			{"action": "COMMENTED", "commentAction": "ADDED", "createdDate": 1462111200000, "user": {"name": "hubot"},
+				"comment": {"text": "LGTM", "createdDate": 1462111200000, "author": {"name": "hubot"}}},
+			{"action": "REVIEWED", "createdDate": 1462109400000, "user": {"name": "spaceghost"}}
+		]}`))
+			return
+		}
+		w.Write([]byte(`{"isLastPage": true, "values": [
+			{"action": "APPROVED", "createdDate": 1462108500000, "user": {"name": "hubot"}},
+			{"action": "COMMENTED", "commentAction": "ADDED", "createdDate": 1462107600000, "user": {"name": "octocat"},
+				"comment": {"text": "please review", "createdDate": 1462107600000, "author": {"name": "octocat"}}},
+			{"action": "OPENED", "createdDate": 1462104000000, "user": {"name": "octocat"}}
+		]}`))
+	case "GET /rest/api/1.0/projects/OCTO/repos/hello-world/pull-requests/1":
+		w.Write([]byte(`{"id": 1, "title": "Update README", "fromRef": {"latestCommit": "6104942438c14ec7bd21c6cd5bd995272b3faff6"}}`))
+	case "GET /rest/api/1.0/projects/OCTO/repos/hello-world/commits/6104942438c14ec7bd21c6cd5bd995272b3faff6":
+		w.Write([]byte(`{"id": "6104942438c14ec7bd21c6cd5bd995272b3faff6", "committerTimestamp": 1462107600000}`))
+	case "GET /rest/api/1.0/projects/OCTO/repos/hello-world/raw/MAINTAINERS":
+		w.Write([]byte("hubot\n"))
+	case "POST /rest/build-status/1.0/commits/6104942438c14ec7bd21c6cd5bd995272b3faff6":
+		w.WriteHeader(204)
+	default:
+		w.WriteHeader(404)
+		w.Write([]byte(`{"errors": [{"message": "Not Found"}]}`))
+	}
+}
+
+var (
+	fakeUser = &model.User{Login: "octocat", Token: "cfcd2084"}
+	fakeRepo = &model.Repo{Owner: "OCTO", Name: "hello-world", Slug: "OCTO/hello-world", Secret: "b9015b08"}
+)
+
+var fakeRepoJSON = `{
+	"slug": "hello-world",
+	"name": "Hello World",
+	"public": false,
+	"project": {"key": "OCTO"},
+	"links": {"self": [{"href": "http://bitbucket.example.com/projects/OCTO/repos/hello-world/browse"}]}
+}`
+
+var fakeCommentHook = `{
+	"eventKey": "pr:comment:added",
+	"actor": {"name": "hubot"},
+	"pullRequest": {
+		"id": 1,
+		"title": "Update README",
+		"author": {"user": {"name": "octocat"}},
+		"toRef": {"repository": {"slug": "hello-world", "project": {"key": "OCTO"}}}
+	},
+	"comment": {"text": "LGTM", "author": {"name": "hubot"}}
+}`
+
+var fakePullHook = `{
+	"eventKey": "pr:reviewer:approved",
+	"actor": {"name": "hubot"},
+	"pullRequest": {
+		"id": 1,
+		"title": "Update README",
+		"author": {"user": {"name": "octocat"}},
+		"toRef": {"repository": {"slug": "hello-world", "project": {"key": "OCTO"}}}
+	}
+}`
@@ -0,0 +1,314 @@
+package bitbucketserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"golang.org/x/oauth2"
+)
+
+const (
+	pathWhoami      = "%splugins/servlet/applinks/whoami"
+	pathUser        = "%srest/api/1.0/users/%s"
+	pathProjects    = "%srest/api/1.0/projects?limit=100"
+	pathProjectPerm = "%srest/api/1.0/projects/%s/permissions/users?limit=100"
+	pathRepos       = "%srest/api/1.0/repos?permission=%s&limit=100"
+	pathRepoPerm    = "%srest/api/1.0/repos?projectkey=%s&name=%s&permission=%s&limit=100"
+	pathRepo        = "%srest/api/1.0/projects/%s/repos/%s"
+	pathDefault     = "%srest/api/1.0/projects/%s/repos/%s/branches/default"
+	pathHooks       = "%srest/api/1.0/projects/%s/repos/%s/webhooks"
+	pathHook        = "%srest/api/1.0/projects/%s/repos/%s/webhooks/%d"
+	pathActivities  = "%srest/api/1.0/projects/%s/repos/%s/pull-requests/%d/activities?limit=100&start=%d"
+	pathPull        = "%srest/api/1.0/projects/%s/repos/%s/pull-requests/%d"
+	pathMergedBy    = "%srest/api/1.0/projects/%s/repos/%s/pull-requests?state=MERGED&role.1=AUTHOR&username.1=%s&limit=1"
+	pathChanges     = "%srest/api/1.0/projects/%s/repos/%s/pull-requests/%d/changes?limit=1000&start=%d"
+	pathCommit      = "%srest/api/1.0/projects/%s/repos/%s/commits/%s"
+	pathRaw         = "%srest/api/1.0/projects/%s/repos/%s/raw/%s"
+	pathRawAt       = "%srest/api/1.0/projects/%s/repos/%s/raw/%s?at=%s"
+	pathRestrict    = "%srest/branch-permissions/2.0/projects/%s/repos/%s/restrictions"
+	pathConditions  = "%srest/required-builds/latest/projects/%s/repos/%s/conditions"
+	pathCondition   = "%srest/required-builds/latest/projects/%s/repos/%s/condition"
+	pathConditionID = "%srest/required-builds/latest/projects/%s/repos/%s/condition/%d"
+	pathStatus      = "%srest/build-status/1.0/commits/%s"
+)
+
+type Client struct {
+	client *http.Client
+	base   string // base url
+}
+
+// NewClient returns a client at the specified url.
+func NewClient(uri string) *Client {
+	return &Client{http.DefaultClient, uri}
+}
+
+// NewClientToken returns a client at the specified url that
+// authenticates all outbound requests with the given token.
+func NewClientToken(uri, token string) *Client {
+	config := new(oauth2.Config)
+	auther := config.Client(oauth2.NoContext, &oauth2.Token{AccessToken: token})
+	return &Client{auther, uri}
+}
+
+// Whoami returns the name of the authenticated user.
+func (c *Client) Whoami() (string, error) {
+	uri := fmt.Sprintf(pathWhoami, c.base)
+	body, err := c.stream(uri, "GET", nil)
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+	out, err := ioutil.ReadAll(body)
+	return strings.TrimSpace(string(out)), err
+}
+
+func (c *Client) User(name string) (*User, error) {
+	out := new(User)
+	uri := fmt.Sprintf(pathUser, c.base, url.QueryEscape(name))
+	err := c.get(uri, out)
+	return out, err
+}
+
+func (c *Client) Projects() ([]*Project, error) {
+	out := new(Projects)
+	uri := fmt.Sprintf(pathProjects, c.base)
+	err := c.get(uri, out)
+	return out.Values, err
+}
+
+func (c *Client) ProjectPerms(key string) ([]*UserPermission, error) {
+	out := new(UserPermissions)
+	uri := fmt.Sprintf(pathProjectPerm, c.base, key)
+	err := c.get(uri, out)
+	return out.Values, err
+}
+
+func (c *Client) Repos(perm string) ([]*Repo, error) {
+	out := new(Repos)
+	uri := fmt.Sprintf(pathRepos, c.base, perm)
+	err := c.get(uri, out)
+	return out.Values, err
+}
+
+// RepoPerm returns true if the authenticated user is granted
+// the permission on the repository.
+func (c *Client) RepoPerm(key, slug, perm string) (bool, error) {
+	out := new(Repos)
+	uri := fmt.Sprintf(pathRepoPerm, c.base, url.QueryEscape(key), url.QueryEscape(slug), perm)
+	err := c.get(uri, out)
+	if err != nil {
+		return false, err
+	}
+	for _, repo := range out.Values {
+		if repo.Slug == slug && strings.EqualFold(repo.Project.Key, key) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (c *Client) Repo(key, slug string) (*Repo, error) {
+	out := new(Repo)
+	uri := fmt.Sprintf(pathRepo, c.base, key, slug)
+	err := c.get(uri, out)
+	return out, err
+}
+
+func (c *Client) DefaultBranch(key, slug string) (*Branch, error) {
+	out := new(Branch)
+	uri := fmt.Sprintf(pathDefault, c.base, key, slug)
+	err := c.get(uri, out)
+	return out, err
+}
+
+func (c *Client) Hooks(key, slug string) ([]*Webhook, error) {
+	out := new(Webhooks)
+	uri := fmt.Sprintf(pathHooks, c.base, key, slug)
+	err := c.get(uri, out)
+	return out.Values, err
+}
+
+func (c *Client) HookCreate(key, slug string, in *Webhook) error {
+	uri := fmt.Sprintf(pathHooks, c.base, key, slug)
+	return c.post(uri, in, nil)
+}
+
+func (c *Client) HookDelete(key, slug string, id int) error {
+	uri := fmt.Sprintf(pathHook, c.base, key, slug, id)
+	return c.delete(uri)
+}
+
+func (c *Client) RestrictionCreate(key, slug string, in *Restriction) error {
+	uri := fmt.Sprintf(pathRestrict, c.base, key, slug)
+	return c.post(uri, in, nil)
+}
+
+func (c *Client) Conditions(key, slug string) ([]*Condition, error) {
+	out := new(Conditions)
+	uri := fmt.Sprintf(pathConditions, c.base, key, slug)
+	err := c.get(uri, out)
+	return out.Values, err
+}
+
+func (c *Client) ConditionCreate(key, slug string, in *Condition) error {
+	uri := fmt.Sprintf(pathCondition, c.base, key, slug)
+	return c.post(uri, in, nil)
+}
+
+func (c *Client) ConditionDelete(key, slug string, id int) error {
+	uri := fmt.Sprintf(pathConditionID, c.base, key, slug, id)
+	return c.delete(uri)
+}
+
+// Activities returns the pull request activities newest first,
+// reading the pages until the last page.
+func (c *Client) Activities(key, slug string, id int) ([]*Activity, error) {
+	var out []*Activity
+	for start := 0; ; {
+		page := new(Activities)
+		uri := fmt.Sprintf(pathActivities, c.base, key, slug, id, start)
+		err := c.get(uri, page)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, page.Values...)
+		if page.IsLastPage {
+			break
+		}
+		start = page.NextPageStart
+	}
+	return out, nil
+}
+
+func (c *Client) PullRequest(key, slug string, id int) (*PullRequest, error) {
+	out := new(PullRequest)
+	uri := fmt.Sprintf(pathPull, c.base, key, slug, id)
+	err := c.get(uri, out)
+	return out, err
+}
+
+func (c *Client) MergedBy(key, slug, username string) ([]*PullRequest, error) {
+	out := new(PullRequests)
+	uri := fmt.Sprintf(pathMergedBy, c.base, key, slug, url.QueryEscape(username))
+	err := c.get(uri, out)
+	return out.Values, err
+}
+
+func (c *Client) Changes(key, slug string, id, start int) (*Changes, error) {
+	out := new(Changes)
+	uri := fmt.Sprintf(pathChanges, c.base, key, slug, id, start)
+	err := c.get(uri, out)
+	return out, err
+}
+
+func (c *Client) Commit(key, slug, sha string) (*Commit, error) {
+	out := new(Commit)
+	uri := fmt.Sprintf(pathCommit, c.base, key, slug, sha)
+	err := c.get(uri, out)
+	return out, err
+}
+
+func (c *Client) Raw(key, slug, path string) ([]byte, error) {
+	return c.raw(fmt.Sprintf(pathRaw, c.base, key, slug, path))
+}
+
+func (c *Client) RawAt(key, slug, path, ref string) ([]byte, error) {
+	return c.raw(fmt.Sprintf(pathRawAt, c.base, key, slug, path, url.QueryEscape(ref)))
+}
+
+// helper function for reading a raw file.
+func (c *Client) raw(uri string) ([]byte, error) {
+	body, err := c.stream(uri, "GET", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	return ioutil.ReadAll(body)
+}
+
+func (c *Client) StatusCreate(sha string, in *Status) error {
+	uri := fmt.Sprintf(pathStatus, c.base, sha)
+	return c.post(uri, in, nil)
+}
+
+//
+// http request helper functions
+//
+
+// helper function for making an http GET request.
+func (c *Client) get(rawurl string, out interface{}) error {
+	return c.do(rawurl, "GET", nil, out)
+}
+
+// helper function for making an http POST request.
+func (c *Client) post(rawurl string, in, out interface{}) error {
+	return c.do(rawurl, "POST", in, out)
+}
+
+// helper function for making an http DELETE request.
+func (c *Client) delete(rawurl string) error {
+	return c.do(rawurl, "DELETE", nil, nil)
+}
+
+// helper function to make an http request
+func (c *Client) do(rawurl, method string, in, out interface{}) error {
+	// executes the http request and returns the body as
+	// and io.ReadCloser
+	body, err := c.stream(rawurl, method, in)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	// if a json response is expected, parse and return
+	// the json response.
+	if out != nil {
+		return json.NewDecoder(body).Decode(out)
+	}
+	return nil
+}
+
+// helper function to stream an http request
+func (c *Client) stream(rawurl, method string, in interface{}) (io.ReadCloser, error) {
+	uri, err := url.Parse(rawurl)
+	if err != nil {
+		return nil, err
+	}
+
+	// if we are posting or putting data, we need to
+	// write it to the body of the request.
+	var buf io.ReadWriter
+	if in != nil {
+		buf = new(bytes.Buffer)
+		err := json.NewEncoder(buf).Encode(in)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// creates a new http request to Bitbucket.
+	req, err := http.NewRequest(method, uri.String(), buf)
+	if err != nil {
+		return nil, err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode > http.StatusPartialContent {
+		defer resp.Body.Close()
+		out, _ := ioutil.ReadAll(resp.Body)
+		return nil, fmt.Errorf("%s", out)
+	}
+	return resp.Body, nil
+}
@@ -0,0 +1,178 @@
+package bitbucketserver
+
+type User struct {
+	Name    string `json:"name"`
+	Slug    string `json:"slug"`
+	Display string `json:"displayName"`
+	Email   string `json:"emailAddress"`
+}
+
+type Project struct {
+	Key  string `json:"key"`
+	Name string `json:"name"`
+}
+
+type Link struct {
+	Href string `json:"href"`
+}
+
+type Repo struct {
+	Slug    string  `json:"slug"`
+	Name    string  `json:"name"`
+	Public  bool    `json:"public"`
+	Project Project `json:"project"`
+	Links   struct {
+		Self []Link `json:"self"`
+	} `json:"links"`
+}
+
+type Repos struct {
+	Values []*Repo `json:"values"`
+}
+
+type Projects struct {
+	Values []*Project `json:"values"`
+}
+
+type UserPermission struct {
+	User       User   `json:"user"`
+	Permission string `json:"permission"`
+}
+
+type UserPermissions struct {
+	Values []*UserPermission `json:"values"`
+}
+
+type Branch struct {
+	ID        string `json:"id"`
+	DisplayID string `json:"displayId"`
+}
+
+type Webhook struct {
+	ID            int               `json:"id,omitempty"`
+	Name          string            `json:"name"`
+	URL           string            `json:"url"`
+	Active        bool              `json:"active"`
+	Events        []string          `json:"events"`
+	Configuration map[string]string `json:"configuration"`
+}
+
+type Webhooks struct {
+	Values []*Webhook `json:"values"`
+}
+
+type Matcher struct {
+	ID   string `json:"id"`
+	Type struct {
+		ID string `json:"id"`
+	} `json:"type"`
+}
+
+type Restriction struct {
+	ID      int     `json:"id,omitempty"`
+	Type    string  `json:"type"`
+	Matcher Matcher `json:"matcher"`
+}
+
+type Condition struct {
+	ID         int      `json:"id,omitempty"`
+	BuildKeys  []string `json:"buildParentKeys"`
+	RefMatcher Matcher  `json:"refMatcher"`
+}
+
+type Conditions struct {
+	Values []*Condition `json:"values"`
+}
+
+type Comment struct {
+	Text    string `json:"text"`
+	Author  User   `json:"author"`
+	Created int64  `json:"createdDate"`
+}
+
+type Activity struct {
+	Action        string   `json:"action"`
+	CommentAction string   `json:"commentAction"`
+	Created       int64    `json:"createdDate"`
+	User          User     `json:"user"`
+	Comment       *Comment `json:"comment"`
+}
+
+type Activities struct {
+	Values        []*Activity `json:"values"`
+	IsLastPage    bool        `json:"isLastPage"`
+	NextPageStart int         `json:"nextPageStart"`
+}
+
+type Changes struct {
+	Values        []*Change `json:"values"`
+	IsLastPage    bool      `json:"isLastPage"`
+	NextPageStart int       `json:"nextPageStart"`
+}
+
+type Change struct {
+	Path struct {
+		ToString string `json:"toString"`
+	} `json:"path"`
+	SrcPath *struct {
+		ToString string `json:"toString"`
+	} `json:"srcPath"`
+}
+
+type PullRequests struct {
+	Values []*PullRequest `json:"values"`
+}
+
+type PullRequest struct {
+	ID      int    `json:"id"`
+	Title   string `json:"title"`
+	Draft   bool   `json:"draft"`
+	Created int64  `json:"createdDate"`
+	FromRef struct {
+		LatestCommit string `json:"latestCommit"`
+	} `json:"fromRef"`
+	ToRef struct {
+		DisplayID    string `json:"displayId"`
+		LatestCommit string `json:"latestCommit"`
+	} `json:"toRef"`
+	Author struct {
+		User User `json:"user"`
+	} `json:"author"`
+}
+
+type Commit struct {
+	ID        string `json:"id"`
+	Committed int64  `json:"committerTimestamp"`
+}
+
+type Status struct {
+	State string `json:"state"`
+	Key   string `json:"key"`
+	Name  string `json:"name"`
+	URL   string `json:"url"`
+	Desc  string `json:"description"`
+}
+
+// hookPullRequest represents the pull request subset of the hook payload.
+type hookPullRequest struct {
+	ID      int    `json:"id"`
+	Title   string `json:"title"`
+	Draft   bool   `json:"draft"`
+	Created int64  `json:"createdDate"`
+	Author  struct {
+		User User `json:"user"`
+	} `json:"author"`
+	ToRef struct {
+		DisplayID    string `json:"displayId"`
+		LatestCommit string `json:"latestCommit"`
+		Repository   Repo   `json:"repository"`
+	} `json:"toRef"`
+}
+
+// pullRequestHook represents a subset of the pull request event payload.
+type pullRequestHook struct {
+	EventKey    string          `json:"eventKey"`
+	Actor       User            `json:"actor"`
+	PullRequest hookPullRequest `json:"pullRequest"`
+	Comment     *Comment        `json:"comment"`
+}
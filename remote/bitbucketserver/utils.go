@@ -0,0 +1,121 @@
+package bitbucketserver
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/lgtmco/lgtm/model"
+)
+
+// getUser is a helper function that retrieves the currently
+// authenticated user. Bitbucket has no endpoint returning the
+// current user, so the user name is retrieved first.
+func getUser(client *Client) (*User, error) {
+	name, err := client.Whoami()
+	if err != nil {
+		return nil, err
+	}
+	if len(name) == 0 {
+		return nil, fmt.Errorf("user is not authenticated")
+	}
+	return client.User(name)
+}
+
+// getHook is a heper function that retrieves a hook by
+// hostname. To do this, it will retrieve a list of all hooks
+// and iterate through the list.
+func getHook(client *Client, owner, name, rawurl string) (*Webhook, error) {
+	hooks, err := client.Hooks(owner, name)
+	if err != nil {
+		return nil, err
+	}
+	newurl, err := url.Parse(rawurl)
+	if err != nil {
+		return nil, err
+	}
+	for _, hook := range hooks {
+		oldurl, err := url.Parse(hook.URL)
+		if err != nil {
+			continue
+		}
+		if newurl.Host == oldurl.Host {
+			return hook, nil
+		}
+	}
+	return nil, nil
+}
+
+// getConditions is a helper function that retrieves the required
+// builds merge checks that require the approval status.
+func getConditions(client *Client, owner, name string) ([]*Condition, error) {
+	conditions, err := client.Conditions(owner, name)
+	if err != nil {
+		return nil, err
+	}
+	var out []*Condition
+	for _, condition := range conditions {
+		for _, key := range condition.BuildKeys {
+			if key == context {
+				out = append(out, condition)
+				break
+			}
+		}
+	}
+	return out, nil
+}
+
+// setCondition is a helper function that requires the approval status
+// before merging into the matching branches, unless already required.
+func setCondition(client *Client, owner, name string, matcher Matcher) error {
+	conditions, err := getConditions(client, owner, name)
+	if err != nil {
+		return err
+	}
+	for _, condition := range conditions {
+		if condition.RefMatcher.ID == matcher.ID {
+			return nil
+		}
+	}
+	return client.ConditionCreate(owner, name, &Condition{
+		BuildKeys:  []string{context},
+		RefMatcher: matcher,
+	})
+}
+
+// branchMatcher is a helper function that returns a ref matcher
+// for the named branch.
+func branchMatcher(ref string) Matcher {
+	matcher := Matcher{ID: ref}
+	matcher.Type.ID = "BRANCH"
+	return matcher
+}
+
+// patternMatcher is a helper function that returns a ref matcher
+// for the branches matching the glob pattern.
+func patternMatcher(pattern string) Matcher {
+	matcher := Matcher{ID: pattern}
+	matcher.Type.ID = "PATTERN"
+	return matcher
+}
+
+// convertRepo is a helper function that converts a Bitbucket
+// repository to the lgtm repository structure.
+func convertRepo(from *Repo) *model.Repo {
+	repo := &model.Repo{
+		Owner:   from.Project.Key,
+		Name:    from.Slug,
+		Slug:    fmt.Sprintf("%s/%s", from.Project.Key, from.Slug),
+		Private: !from.Public,
+	}
+	if len(from.Links.Self) != 0 {
+		repo.Link = from.Links.Self[0].Href
+	}
+	return repo
+}
+
+// toTime is a helper function that converts the millisecond
+// timestamps used by Bitbucket to time.
+func toTime(ms int64) time.Time {
+	return time.Unix(0, ms*int64(time.Millisecond))
+}
@@ -2,7 +2,14 @@ package remote
 
 import "golang.org/x/net/context"
 
-const key = "remote"
+const (
+	key     = "remote"
+	keyList = "remotes"
+)
+
+// Remotes is a set of Remote clients keyed by name. The Remote client
+// with an empty name is the default client.
+type Remotes map[string]Remote
 
 // Setter defines a context that enables setting values.
 type Setter interface {
@@ -19,3 +26,37 @@ func FromContext(c context.Context) Remote {
 func ToContext(c Setter, client Remote) {
 	c.Set(key, client)
 }
+
+// RemotesFromContext returns the named Remote clients associated
+// with this context.
+func RemotesFromContext(c context.Context) Remotes {
+	remotes, _ := c.Value(keyList).(Remotes)
+	return remotes
+}
+
+// RemotesToContext adds the named Remote clients to this context if
+// it supports the Setter interface. The default client is added as
+// the Remote client associated with this context.
+func RemotesToContext(c Setter, remotes Remotes) {
+	c.Set(keyList, remotes)
+	c.Set(key, remotes[""])
+}
+
+// Use replaces the Remote client associated with this context with
+// the named Remote client. It returns false if there is no Remote
+// client with the given name.
+func Use(c interface {
+	context.Context
+	Setter
+}, name string) bool {
+	remotes := RemotesFromContext(c)
+	if remotes == nil {
+		return len(name) == 0
+	}
+	client, ok := remotes[name]
+	if !ok {
+		return false
+	}
+	ToContext(c, client)
+	return true
+}
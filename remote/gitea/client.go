@@ -0,0 +1,309 @@
+package gitea
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+
+	"golang.org/x/oauth2"
+)
+
+const (
+	pathUser       = "%suser"
+	pathUserLogin  = "%susers/%s"
+	pathOrgs       = "%suser/orgs?limit=50"
+	pathRepos      = "%suser/repos?limit=50"
+	pathTeams      = "%sorgs/%s/teams?limit=50"
+	pathMembers    = "%steams/%d/members?limit=50"
+	pathRepo       = "%srepos/%s/%s"
+	pathHooks      = "%srepos/%s/%s/hooks"
+	pathHook       = "%srepos/%s/%s/hooks/%d"
+	pathBranches   = "%srepos/%s/%s/branches?limit=50&page=%d"
+	pathProtection = "%srepos/%s/%s/branch_protections"
+	pathProtect    = "%srepos/%s/%s/branch_protections/%s"
+	pathComments   = "%srepos/%s/%s/issues/%d/comments"
+	pathReviews    = "%srepos/%s/%s/pulls/%d/reviews?limit=50"
+	pathPull       = "%srepos/%s/%s/pulls/%d"
+	pathFiles      = "%srepos/%s/%s/pulls/%d/files?limit=50&page=%d"
+	pathCommit     = "%srepos/%s/%s/git/commits/%s"
+	pathContents   = "%srepos/%s/%s/contents/%s"
+	pathContentsAt = "%srepos/%s/%s/contents/%s?ref=%s"
+	pathStatus     = "%srepos/%s/%s/statuses/%s"
+	pathOrgMember  = "%sorgs/%s/members/%s"
+	pathPullsBy    = "%srepos/%s/%s/issues?type=pulls&state=closed&created_by=%s&limit=50"
+)
+
+type Client struct {
+	client *http.Client
+	base   string // base url
+}
+
+// NewClient returns a client at the specified url.
+func NewClient(uri string) *Client {
+	return &Client{http.DefaultClient, uri}
+}
+
+// NewClientToken returns a client at the specified url that
+// authenticates all outbound requests with the given token.
+func NewClientToken(uri, token string) *Client {
+	config := new(oauth2.Config)
+	auther := config.Client(oauth2.NoContext, &oauth2.Token{AccessToken: token})
+	return &Client{auther, uri}
+}
+
+func (c *Client) User() (*User, error) {
+	out := new(User)
+	uri := fmt.Sprintf(pathUser, c.base)
+	err := c.get(uri, out)
+	return out, err
+}
+
+func (c *Client) UserLogin(login string) (*User, error) {
+	out := new(User)
+	uri := fmt.Sprintf(pathUserLogin, c.base, url.QueryEscape(login))
+	err := c.get(uri, out)
+	return out, err
+}
+
+func (c *Client) Orgs() ([]*Org, error) {
+	out := []*Org{}
+	uri := fmt.Sprintf(pathOrgs, c.base)
+	err := c.get(uri, &out)
+	return out, err
+}
+
+func (c *Client) Teams(org string) ([]*Team, error) {
+	out := []*Team{}
+	uri := fmt.Sprintf(pathTeams, c.base, org)
+	err := c.get(uri, &out)
+	return out, err
+}
+
+func (c *Client) Members(team int64) ([]*User, error) {
+	out := []*User{}
+	uri := fmt.Sprintf(pathMembers, c.base, team)
+	err := c.get(uri, &out)
+	return out, err
+}
+
+func (c *Client) Repos() ([]*Repo, error) {
+	out := []*Repo{}
+	uri := fmt.Sprintf(pathRepos, c.base)
+	err := c.get(uri, &out)
+	return out, err
+}
+
+func (c *Client) Repo(owner, name string) (*Repo, error) {
+	out := new(Repo)
+	uri := fmt.Sprintf(pathRepo, c.base, owner, name)
+	err := c.get(uri, out)
+	return out, err
+}
+
+func (c *Client) Hooks(owner, name string) ([]*Hook, error) {
+	out := []*Hook{}
+	uri := fmt.Sprintf(pathHooks, c.base, owner, name)
+	err := c.get(uri, &out)
+	return out, err
+}
+
+func (c *Client) HookCreate(owner, name string, in *Hook) error {
+	uri := fmt.Sprintf(pathHooks, c.base, owner, name)
+	return c.post(uri, in, nil)
+}
+
+func (c *Client) HookDelete(owner, name string, id int64) error {
+	uri := fmt.Sprintf(pathHook, c.base, owner, name, id)
+	return c.delete(uri)
+}
+
+func (c *Client) Branches(owner, name string, page int) ([]*Branch, error) {
+	out := []*Branch{}
+	uri := fmt.Sprintf(pathBranches, c.base, owner, name, page)
+	err := c.get(uri, &out)
+	return out, err
+}
+
+func (c *Client) BranchProtection(owner, name, branch string) (*BranchProtection, error) {
+	out := new(BranchProtection)
+	uri := fmt.Sprintf(pathProtect, c.base, owner, name, url.QueryEscape(branch))
+	err := c.get(uri, out)
+	return out, err
+}
+
+func (c *Client) BranchProtectionCreate(owner, name string, in *BranchProtection) error {
+	uri := fmt.Sprintf(pathProtection, c.base, owner, name)
+	return c.post(uri, in, nil)
+}
+
+func (c *Client) BranchProtectionEdit(owner, name string, in *BranchProtection) error {
+	uri := fmt.Sprintf(pathProtect, c.base, owner, name, url.QueryEscape(in.Branch))
+	return c.patch(uri, in, nil)
+}
+
+func (c *Client) Comments(owner, name string, num int) ([]*Comment, error) {
+	out := []*Comment{}
+	uri := fmt.Sprintf(pathComments, c.base, owner, name, num)
+	err := c.get(uri, &out)
+	return out, err
+}
+
+func (c *Client) Reviews(owner, name string, num int) ([]*Review, error) {
+	out := []*Review{}
+	uri := fmt.Sprintf(pathReviews, c.base, owner, name, num)
+	err := c.get(uri, &out)
+	return out, err
+}
+
+func (c *Client) PullRequest(owner, name string, num int) (*PullRequest, error) {
+	out := new(PullRequest)
+	uri := fmt.Sprintf(pathPull, c.base, owner, name, num)
+	err := c.get(uri, out)
+	return out, err
+}
+
+func (c *Client) PullRequestsBy(owner, name, login string) ([]*Issue, error) {
+	out := []*Issue{}
+	uri := fmt.Sprintf(pathPullsBy, c.base, owner, name, url.QueryEscape(login))
+	err := c.get(uri, &out)
+	return out, err
+}
+
+// OrgMember returns true if the user is a member of the org. The
+// org api responds with no content for members, and not found
+// for other users.
+func (c *Client) OrgMember(org, login string) (bool, error) {
+	uri := fmt.Sprintf(pathOrgMember, c.base, org, url.QueryEscape(login))
+	resp, err := c.client.Get(uri)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusNoContent:
+		return true, nil
+	case http.StatusNotFound:
+		return false, nil
+	}
+	out, _ := ioutil.ReadAll(resp.Body)
+	return false, fmt.Errorf("%s", out)
+}
+
+func (c *Client) Files(owner, name string, num, page int) ([]*ChangedFile, error) {
+	out := []*ChangedFile{}
+	uri := fmt.Sprintf(pathFiles, c.base, owner, name, num, page)
+	err := c.get(uri, &out)
+	return out, err
+}
+
+func (c *Client) Commit(owner, name, sha string) (*Commit, error) {
+	out := new(Commit)
+	uri := fmt.Sprintf(pathCommit, c.base, owner, name, sha)
+	err := c.get(uri, out)
+	return out, err
+}
+
+func (c *Client) Contents(owner, name, path string) (*Content, error) {
+	out := new(Content)
+	uri := fmt.Sprintf(pathContents, c.base, owner, name, path)
+	err := c.get(uri, out)
+	return out, err
+}
+
+func (c *Client) ContentsAt(owner, name, path, ref string) (*Content, error) {
+	out := new(Content)
+	uri := fmt.Sprintf(pathContentsAt, c.base, owner, name, path, url.QueryEscape(ref))
+	err := c.get(uri, out)
+	return out, err
+}
+
+func (c *Client) StatusCreate(owner, name, sha string, in *Status) error {
+	uri := fmt.Sprintf(pathStatus, c.base, owner, name, sha)
+	return c.post(uri, in, nil)
+}
+
+//
+// http request helper functions
+//
+
+// helper function for making an http GET request.
+func (c *Client) get(rawurl string, out interface{}) error {
+	return c.do(rawurl, "GET", nil, out)
+}
+
+// helper function for making an http POST request.
+func (c *Client) post(rawurl string, in, out interface{}) error {
+	return c.do(rawurl, "POST", in, out)
+}
+
+// helper function for making an http PATCH request.
+func (c *Client) patch(rawurl string, in, out interface{}) error {
+	return c.do(rawurl, "PATCH", in, out)
+}
+
+// helper function for making an http DELETE request.
+func (c *Client) delete(rawurl string) error {
+	return c.do(rawurl, "DELETE", nil, nil)
+}
+
+// helper function to make an http request
+func (c *Client) do(rawurl, method string, in, out interface{}) error {
+	// executes the http request and returns the body as
+	// and io.ReadCloser
+	body, err := c.stream(rawurl, method, in, out)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	// if a json response is expected, parse and return
+	// the json response.
+	if out != nil {
+		return json.NewDecoder(body).Decode(out)
+	}
+	return nil
+}
+
+// helper function to stream an http request
+func (c *Client) stream(rawurl, method string, in, out interface{}) (io.ReadCloser, error) {
+	uri, err := url.Parse(rawurl)
+	if err != nil {
+		return nil, err
+	}
+
+	// if we are posting or putting data, we need to
+	// write it to the body of the request.
+	var buf io.ReadWriter
+	if in != nil {
+		buf = new(bytes.Buffer)
+		err := json.NewEncoder(buf).Encode(in)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// creates a new http request to Gitea.
+	req, err := http.NewRequest(method, uri.String(), buf)
+	if err != nil {
+		return nil, err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode > http.StatusPartialContent {
+		defer resp.Body.Close()
+		out, _ := ioutil.ReadAll(resp.Body)
+		return nil, fmt.Errorf("%s", out)
+	}
+	return resp.Body, nil
+}
@@ -0,0 +1,555 @@
+package gitea
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/lgtmco/lgtm/model"
+	"github.com/lgtmco/lgtm/shared/httputil"
+	"golang.org/x/oauth2"
+)
+
+// name of the status message posted to Gitea
+const context = "approvals/lgtm"
+
+type Gitea struct {
+	URL    string
+	API    string
+	Client string
+	Secret string
+}
+
+func (g *Gitea) GetUser(res http.ResponseWriter, req *http.Request) (*model.User, error) {
+
+	var config = &oauth2.Config{
+		ClientID:     g.Client,
+		ClientSecret: g.Secret,
+		RedirectURL:  fmt.Sprintf("%s/login", httputil.GetURL(req)),
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  fmt.Sprintf("%s/login/oauth/authorize", g.URL),
+			TokenURL: fmt.Sprintf("%s/login/oauth/access_token", g.URL),
+		},
+	}
+
+	// get the oauth code from the incoming request. if no code is present
+	// redirec the user to Gitea login to retrieve a code.
+	var code = req.FormValue("code")
+	if len(code) == 0 {
+		state := fmt.Sprintln(time.Now().Unix())
+		http.Redirect(res, req, config.AuthCodeURL(state), http.StatusSeeOther)
+		return nil, nil
+	}
+
+	// exchanges the oauth2 code for an access token
+	token, err := config.Exchange(oauth2.NoContext, code)
+	if err != nil {
+		return nil, fmt.Errorf("Error exchanging token. %s", err)
+	}
+
+	// get the currently authenticated user details for the access token
+	client := NewClientToken(g.API, token.AccessToken)
+	user, err := client.User()
+	if err != nil {
+		return nil, fmt.Errorf("Error fetching user. %s", err)
+	}
+
+	return &model.User{
+		Login:  user.Login,
+		Token:  token.AccessToken,
+		Avatar: user.Avatar,
+	}, nil
+}
+
+func (g *Gitea) GetUserToken(token string) (string, error) {
+	client := NewClientToken(g.API, token)
+	user, err := client.User()
+	if err != nil {
+		return "", fmt.Errorf("Error fetching user. %s", err)
+	}
+	return user.Login, nil
+}
+
+func (g *Gitea) GetTeams(user *model.User) ([]*model.Team, error) {
+	client := NewClientToken(g.API, user.Token)
+	orgs, err := client.Orgs()
+	if err != nil {
+		return nil, fmt.Errorf("Error fetching teams. %s", err)
+	}
+	teams := []*model.Team{}
+	for _, org := range orgs {
+		teams = append(teams, &model.Team{
+			Login:  org.Username,
+			Avatar: org.Avatar,
+		})
+	}
+	return teams, nil
+}
+
+func (g *Gitea) GetMembers(user *model.User, team string) ([]*model.Member, error) {
+	org, name := splitTeam(team)
+	client := NewClientToken(g.API, user.Token)
+	teams, err := client.Teams(org)
+	if err != nil {
+		return nil, fmt.Errorf("Error accessing team list. %s", err)
+	}
+	var id int64
+	for _, team := range teams {
+		if strings.ToLower(team.Name) == name {
+			id = team.ID
+			break
+		}
+	}
+	if id == 0 {
+		return nil, fmt.Errorf("Error finding approvers team for %s", team)
+	}
+	teammates, err := client.Members(id)
+	if err != nil {
+		return nil, fmt.Errorf("Error fetching team members. %s", err)
+	}
+	var members []*model.Member
+	for _, teammate := range teammates {
+		members = append(members, &model.Member{
+			Login: teammate.Login,
+		})
+	}
+	return members, nil
+}
+
+func (g *Gitea) GetMember(user *model.User, login string) (*model.Member, error) {
+	client := NewClientToken(g.API, user.Token)
+	account, err := client.UserLogin(login)
+	if err != nil {
+		return nil, err
+	}
+	return &model.Member{
+		Login: account.Login,
+	}, nil
+}
+
+func (g *Gitea) GetRepo(user *model.User, owner, name string) (*model.Repo, error) {
+	client := NewClientToken(g.API, user.Token)
+	repo_, err := client.Repo(owner, name)
+	if err != nil {
+		return nil, fmt.Errorf("Error fetching repository. %s", err)
+	}
+	return &model.Repo{
+		Owner:   owner,
+		Name:    name,
+		Slug:    repo_.FullName,
+		Link:    repo_.HTMLURL,
+		Private: repo_.Private,
+	}, nil
+}
+
+func (g *Gitea) GetPerm(user *model.User, owner, name string) (*model.Perm, error) {
+	client := NewClientToken(g.API, user.Token)
+	repo, err := client.Repo(owner, name)
+	if err != nil {
+		return nil, fmt.Errorf("Error fetching repository. %s", err)
+	}
+	m := &model.Perm{}
+	m.Admin = repo.Permissions.Admin
+	m.Push = repo.Permissions.Push
+	m.Pull = repo.Permissions.Pull
+	return m, nil
+}
+
+func (g *Gitea) GetRepos(u *model.User) ([]*model.Repo, error) {
+	client := NewClientToken(g.API, u.Token)
+	all, err := client.Repos()
+	if err != nil {
+		return nil, err
+	}
+
+	repos := []*model.Repo{}
+	for _, repo := range all {
+		// only list repositories that I can admin
+		if !repo.Permissions.Admin {
+			continue
+		}
+		repos = append(repos, &model.Repo{
+			Owner:   repo.Owner.Login,
+			Name:    repo.Name,
+			Slug:    repo.FullName,
+			Link:    repo.HTMLURL,
+			Private: repo.Private,
+		})
+	}
+	return repos, nil
+}
+
+func (g *Gitea) SetHook(user *model.User, repo *model.Repo, link string) error {
+	client := NewClientToken(g.API, user.Token)
+
+	repo_, err := client.Repo(repo.Owner, repo.Name)
+	if err != nil {
+		return err
+	}
+
+	old, err := getHook(client, repo.Owner, repo.Name, link)
+	if err == nil && old != nil {
+		client.HookDelete(repo.Owner, repo.Name, old.ID)
+	}
+
+	err = client.HookCreate(repo.Owner, repo.Name, &Hook{
+		Type: "gitea",
+		Config: map[string]string{
+			"url":          link,
+			"content_type": "json",
+			"secret":       repo.Secret,
+		},
+		Events: []string{"issue_comment", "pull_request", "pull_request_sync", "pull_request_review"},
+		Active: true,
+	})
+	if err != nil {
+		log.Debugf("Error creating the webhook at %s. %s", link, err)
+		return err
+	}
+
+	err = protectBranch(client, repo.Owner, repo.Name, repo_.DefaultBranch)
+	if err != nil {
+		log.Warnf("Error configuring protected branch for %s/%s@%s. %s", repo.Owner, repo.Name, repo_.DefaultBranch, err)
+	}
+	return nil
+}
+
+func (g *Gitea) SetBranches(user *model.User, repo *model.Repo, patterns []string) error {
+	client := NewClientToken(g.API, user.Token)
+
+	for page := 1; ; page++ {
+		branches, err := client.Branches(repo.Owner, repo.Name, page)
+		if err != nil {
+			return fmt.Errorf("Error fetching branches. %s", err)
+		}
+		for _, branch := range branches {
+			if !model.MatchBranch(patterns, branch.Name) {
+				continue
+			}
+			err = protectBranch(client, repo.Owner, repo.Name, branch.Name)
+			if err != nil {
+				return fmt.Errorf("Error configuring protected branch %s. %s", branch.Name, err)
+			}
+		}
+		if len(branches) < 50 {
+			break
+		}
+	}
+	return nil
+}
+
+func (g *Gitea) DelHook(user *model.User, repo *model.Repo, link string) error {
+	client := NewClientToken(g.API, user.Token)
+
+	hook, err := getHook(client, repo.Owner, repo.Name, link)
+	if err != nil {
+		return err
+	} else if hook == nil {
+		return nil
+	}
+	err = client.HookDelete(repo.Owner, repo.Name, hook.ID)
+	if err != nil {
+		return err
+	}
+
+	repo_, err := client.Repo(repo.Owner, repo.Name)
+	if err != nil {
+		return err
+	}
+	protection, err := client.BranchProtection(repo.Owner, repo.Name, repo_.DefaultBranch)
+	if err != nil || len(protection.StatusContexts) == 0 {
+		return nil
+	}
+	checks := []string{}
+	for _, check := range protection.StatusContexts {
+		if check != context {
+			checks = append(checks, check)
+		}
+	}
+	protection.StatusContexts = checks
+	protection.EnableStatus = len(checks) != 0
+	return client.BranchProtectionEdit(repo.Owner, repo.Name, protection)
+}
+
+// GetComments returns the pull request comments. Gitea lists comments
+// in chronological order, which are reversed to match the newest
+// first order used by the other remotes.
+func (g *Gitea) GetComments(u *model.User, r *model.Repo, num int) ([]*model.Comment, error) {
+	client := NewClientToken(g.API, u.Token)
+
+	comments_, err := client.Comments(r.Owner, r.Name, num)
+	if err != nil {
+		return nil, err
+	}
+	comments := []*model.Comment{}
+	for i := len(comments_) - 1; i >= 0; i-- {
+		comment := comments_[i]
+		comments = append(comments, &model.Comment{
+			Author:  comment.User.Login,
+			Body:    comment.Body,
+			Created: comment.Created,
+		})
+	}
+	return comments, nil
+}
+
+func (g *Gitea) GetReviews(u *model.User, r *model.Repo, num int) ([]*model.Review, error) {
+	client := NewClientToken(g.API, u.Token)
+
+	reviews_, err := client.Reviews(r.Owner, r.Name, num)
+	if err != nil {
+		return nil, err
+	}
+	reviews := []*model.Review{}
+	for _, review := range reviews_ {
+		state := review.State
+		switch {
+		case review.Dismissed:
+			state = model.ReviewDismissed
+		case state == "REQUEST_CHANGES":
+			state = model.ReviewChangesRequested
+		case state == "COMMENT":
+			state = model.ReviewCommented
+		}
+		reviews = append(reviews, &model.Review{
+			Author:  review.User.Login,
+			State:   state,
+			Created: review.Submitted,
+		})
+	}
+	return reviews, nil
+}
+
+func (g *Gitea) GetContentsRef(u *model.User, r *model.Repo, path, ref string) ([]byte, error) {
+	client := NewClientToken(g.API, u.Token)
+	var (
+		content *Content
+		err     error
+	)
+	if len(ref) == 0 {
+		content, err = client.Contents(r.Owner, r.Name, path)
+	} else {
+		content, err = client.ContentsAt(r.Owner, r.Name, path, ref)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if content.Encoding != "base64" {
+		return []byte(content.Content), nil
+	}
+	return base64.StdEncoding.DecodeString(content.Content)
+}
+
+func (g *Gitea) GetHead(u *model.User, r *model.Repo, num int) (*model.Commit, error) {
+	client := NewClientToken(g.API, u.Token)
+
+	pr, err := client.PullRequest(r.Owner, r.Name, num)
+	if err != nil {
+		return nil, err
+	}
+	commit, err := client.Commit(r.Owner, r.Name, pr.Head.SHA)
+	if err != nil {
+		return nil, err
+	}
+	return &model.Commit{
+		SHA:     commit.SHA,
+		Created: commit.Commit.Committer.Date,
+	}, nil
+}
+
+func (g *Gitea) GetIssue(u *model.User, r *model.Repo, num int) (*model.Issue, error) {
+	client := NewClientToken(g.API, u.Token)
+
+	pr, err := client.PullRequest(r.Owner, r.Name, num)
+	if err != nil {
+		return nil, err
+	}
+	return &model.Issue{
+		Number:  num,
+		Title:   pr.Title,
+		Author:  pr.User.Login,
+		Branch:  pr.Base.Ref,
+		Base:    pr.Base.SHA,
+		Draft:   pr.Draft,
+		Created: pr.Created,
+	}, nil
+}
+
+func (g *Gitea) GetAuthor(u *model.User, r *model.Repo, login string) (*model.Author, error) {
+	client := NewClientToken(g.API, u.Token)
+
+	author := &model.Author{Login: login}
+	if strings.EqualFold(r.Owner, login) {
+		author.Member = true
+	} else {
+		member, err := client.OrgMember(r.Owner, login)
+		if err != nil {
+			return nil, fmt.Errorf("Error fetching org membership. %s", err)
+		}
+		author.Member = member
+	}
+	pulls, err := client.PullRequestsBy(r.Owner, r.Name, login)
+	if err != nil {
+		return nil, fmt.Errorf("Error fetching pull requests. %s", err)
+	}
+	author.FirstTime = true
+	for _, pull := range pulls {
+		if pull.PullRequest != nil && pull.PullRequest.Merged {
+			author.FirstTime = false
+			break
+		}
+	}
+	return author, nil
+}
+
+func (g *Gitea) GetFiles(u *model.User, r *model.Repo, num int) ([]string, error) {
+	client := NewClientToken(g.API, u.Token)
+
+	files := []string{}
+	for page := 1; ; page++ {
+		changed, err := client.Files(r.Owner, r.Name, num, page)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range changed {
+			files = append(files, file.Filename)
+			if len(file.PreviousFilename) != 0 {
+				files = append(files, file.PreviousFilename)
+			}
+		}
+		if len(changed) < 50 {
+			break
+		}
+	}
+	return files, nil
+}
+
+func (g *Gitea) SetStatus(u *model.User, r *model.Repo, num int, s *model.Status) error {
+	client := NewClientToken(g.API, u.Token)
+
+	pr, err := client.PullRequest(r.Owner, r.Name, num)
+	if err != nil {
+		return err
+	}
+
+	status := "success"
+	desc := "this commit looks good"
+
+	switch {
+	case s.WorkInProgress:
+		status = "pending"
+		desc = "work in progress"
+	case s.IsVetoed():
+		status = "failure"
+		desc = fmt.Sprintf("blocked by %s", strings.Join(s.Vetoes, ", "))
+	case len(s.Blockers) != 0:
+		status = "pending"
+		desc = fmt.Sprintf("changes requested by %s", strings.Join(s.Blockers, ", "))
+	case s.Granted < s.Required:
+		status = "pending"
+		desc = s.Summary()
+	case !s.WindowEnds.IsZero():
+		status = "pending"
+		desc = s.WindowSummary()
+	}
+
+	name := context
+	if len(s.Context) != 0 {
+		name = s.Context
+	}
+
+	return client.StatusCreate(r.Owner, r.Name, pr.Head.SHA, &Status{
+		State:   status,
+		Context: name,
+		Desc:    desc,
+	})
+}
+
+func (g *Gitea) GetHook(r *http.Request) (*model.Hook, error) {
+	event := r.Header.Get("X-Gitea-Event")
+	if len(event) == 0 {
+		event = r.Header.Get("X-Gogs-Event")
+	}
+
+	switch event {
+	case "issue_comment":
+		return parseCommentHook(r)
+	case "pull_request", "pull_request_sync",
+		"pull_request_approved", "pull_request_rejected",
+		"pull_request_review_approved", "pull_request_review_rejected":
+		return parsePullRequestHook(r, event)
+	default:
+		return nil, nil
+	}
+}
+
+// GetAppUser returns nil, as Gitea does not support authenticating
+// as an application.
+func (g *Gitea) GetAppUser(r *model.Repo) (*model.User, error) {
+	return nil, nil
+}
+
+func (g *Gitea) SetInstallation(r *http.Request) error {
+	return nil
+}
+
+func parseCommentHook(r *http.Request) (*model.Hook, error) {
+	data := commentHook{}
+	err := json.NewDecoder(r.Body).Decode(&data)
+	if err != nil {
+		return nil, err
+	}
+
+	if !data.IsPull && data.Issue.PullRequest == nil {
+		return nil, nil
+	}
+
+	hook := new(model.Hook)
+	hook.Issue = new(model.Issue)
+	hook.Issue.Number = data.Issue.Number
+	hook.Issue.Title = data.Issue.Title
+	hook.Issue.Author = data.Issue.User.Login
+	hook.Repo = new(model.Repo)
+	hook.Repo.Owner = data.Repository.Owner.login()
+	hook.Repo.Name = data.Repository.Name
+	hook.Repo.Slug = data.Repository.FullName
+	hook.Comment = new(model.Comment)
+	hook.Comment.Body = data.Comment.Body
+	hook.Comment.Author = data.Comment.User.Login
+
+	return hook, nil
+}
+
+func parsePullRequestHook(r *http.Request, event string) (*model.Hook, error) {
+	data := pullRequestHook{}
+	err := json.NewDecoder(r.Body).Decode(&data)
+	if err != nil {
+		return nil, err
+	}
+
+	// only new commits pushed to the pull request, changes to its
+	// title, and reviews, require the approval status to be re-evaluated.
+	if event == "pull_request" && data.Action != "synchronized" && data.Action != "edited" {
+		return nil, nil
+	}
+
+	hook := new(model.Hook)
+	hook.Issue = new(model.Issue)
+	hook.Issue.Number = data.PullRequest.Number
+	hook.Issue.Title = data.PullRequest.Title
+	hook.Issue.Author = data.PullRequest.User.Login
+	hook.Issue.Branch = data.PullRequest.Base.Ref
+	hook.Issue.Base = data.PullRequest.Base.SHA
+	hook.Issue.Draft = data.PullRequest.Draft
+	hook.Issue.Created = data.PullRequest.Created
+	hook.Repo = new(model.Repo)
+	hook.Repo.Owner = data.Repository.Owner.login()
+	hook.Repo.Name = data.Repository.Name
+	hook.Repo.Slug = data.Repository.FullName
+
+	return hook, nil
+}
@@ -0,0 +1,322 @@
+package gitea
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lgtmco/lgtm/model"
+
+	"github.com/franela/goblin"
+)
+
+func TestGitea(t *testing.T) {
+
+	var server *httptest.Server
+	var requests []*http.Request
+	var payloads [][]byte
+
+	g := goblin.Goblin(t)
+	g.Describe("Gitea", func() {
+
+		var gitea *Gitea
+
+		g.Before(func() {
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := ioutil.ReadAll(r.Body)
+				requests = append(requests, r)
+				payloads = append(payloads, body)
+				fakeServer(w, r)
+			}))
+		})
+		g.After(func() {
+			server.Close()
+		})
+		g.BeforeEach(func() {
+			requests = nil
+			payloads = nil
+			gitea = &Gitea{
+				URL: server.URL,
+				API: server.URL + "/api/v1/",
+			}
+		})
+
+		g.It("Should get the maintainers team members", func() {
+			members, err := gitea.GetMembers(fakeUser, "octocat")
+			g.Assert(err == nil).IsTrue()
+			g.Assert(len(members)).Equal(1)
+			g.Assert(members[0].Login).Equal("hubot")
+		})
+
+		g.It("Should get the members of a named team", func() {
+			members, err := gitea.GetMembers(fakeUser, "octocat/owners")
+			g.Assert(err == nil).IsTrue()
+			g.Assert(len(members)).Equal(1)
+			g.Assert(members[0].Login).Equal("octocat")
+		})
+
+		g.It("Should get the repository permissions", func() {
+			perm, err := gitea.GetPerm(fakeUser, "octocat", "hello-world")
+			g.Assert(err == nil).IsTrue()
+			g.Assert(perm.Pull).IsTrue()
+			g.Assert(perm.Push).IsTrue()
+			g.Assert(perm.Admin).IsFalse()
+		})
+
+		g.It("Should create the hook and protect the branch", func() {
+			err := gitea.SetHook(fakeUser, fakeRepo, "http://lgtm.example.com/hook?access_token=x")
+			g.Assert(err == nil).IsTrue()
+
+			var created, protected bool
+			for i, r := range requests {
+				switch {
+				case r.Method == "POST" && r.URL.Path == "/api/v1/repos/octocat/hello-world/hooks":
+					hook := new(Hook)
+					json.Unmarshal(payloads[i], hook)
+					g.Assert(hook.Config["secret"]).Equal(fakeRepo.Secret)
+					created = true
+				case r.Method == "PATCH" && r.URL.Path == "/api/v1/repos/octocat/hello-world/branch_protections/master":
+					protection := new(BranchProtection)
+					json.Unmarshal(payloads[i], protection)
+					g.Assert(protection.StatusContexts).Equal([]string{"ci/drone", "approvals/lgtm"})
+					protected = true
+				}
+			}
+			g.Assert(created).IsTrue()
+			g.Assert(protected).IsTrue()
+		})
+
+		g.It("Should protect the branches matching the patterns", func() {
+			err := gitea.SetBranches(fakeUser, fakeRepo, []string{"release/*"})
+			g.Assert(err == nil).IsTrue()
+
+			var protected []string
+			for i, r := range requests {
+				if r.Method == "POST" && r.URL.Path == "/api/v1/repos/octocat/hello-world/branch_protections" {
+					protection := new(BranchProtection)
+					json.Unmarshal(payloads[i], protection)
+					g.Assert(protection.StatusContexts).Equal([]string{"approvals/lgtm"})
+					protected = append(protected, protection.Branch)
+				}
+			}
+			g.Assert(protected).Equal([]string{"release/1.0", "release/1.1"})
+		})
+
+		g.It("Should get the pull request", func() {
+			issue, err := gitea.GetIssue(fakeUser, fakeRepo, 1)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(issue.Author).Equal("octocat")
+			g.Assert(issue.Branch).Equal("master")
+			g.Assert(issue.Base).Equal("553c2077f0edc3d5dc5d17262f6aa498e69d6f8e")
+		})
+
+		g.It("Should classify the pull request author", func() {
+			author, err := gitea.GetAuthor(fakeUser, fakeRepo, "hubot")
+			g.Assert(err == nil).IsTrue()
+			g.Assert(author.Member).IsTrue()
+			g.Assert(author.FirstTime).IsFalse()
+
+			author, err = gitea.GetAuthor(fakeUser, fakeRepo, "spaceghost")
+			g.Assert(err == nil).IsTrue()
+			g.Assert(author.Member).IsFalse()
+			g.Assert(author.FirstTime).IsTrue()
+		})
+
+		g.It("Should get the changed files", func() {
+			files, err := gitea.GetFiles(fakeUser, fakeRepo, 1)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(files).Equal([]string{"README.md", "docs/index.md", "index.md"})
+		})
+
+		g.It("Should get the comments newest first", func() {
+			comments, err := gitea.GetComments(fakeUser, fakeRepo, 1)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(len(comments)).Equal(2)
+			g.Assert(comments[0].Author).Equal("hubot")
+			g.Assert(comments[1].Author).Equal("octocat")
+		})
+
+		g.It("Should get the reviews", func() {
+			reviews, err := gitea.GetReviews(fakeUser, fakeRepo, 1)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(len(reviews)).Equal(3)
+			g.Assert(reviews[0].State).Equal(model.ReviewApproved)
+			g.Assert(reviews[1].State).Equal(model.ReviewChangesRequested)
+			g.Assert(reviews[2].State).Equal(model.ReviewDismissed)
+		})
+
+		g.It("Should get the file contents", func() {
+			data, err := gitea.GetContentsRef(fakeUser, fakeRepo, "MAINTAINERS", "")
+			g.Assert(err == nil).IsTrue()
+			g.Assert(string(data)).Equal("hubot\n")
+		})
+
+		g.It("Should get the file contents at a ref", func() {
+			data, err := gitea.GetContentsRef(fakeUser, fakeRepo, "MAINTAINERS", "release/1.0")
+			g.Assert(err == nil).IsTrue()
+			g.Assert(string(data)).Equal("octocat\n")
+		})
+
+		g.It("Should get a member by login", func() {
+			member, err := gitea.GetMember(fakeUser, "hubot")
+			g.Assert(err == nil).IsTrue()
+			g.Assert(member.Login).Equal("hubot")
+
+			_, err = gitea.GetMember(fakeUser, "ghost")
+			g.Assert(err != nil).IsTrue()
+		})
+
+		g.It("Should set the commit status", func() {
+			err := gitea.SetStatus(fakeUser, fakeRepo, 1, &model.Status{Granted: 2, Required: 2})
+			g.Assert(err == nil).IsTrue()
+
+			status := new(Status)
+			json.Unmarshal(payloads[len(payloads)-1], status)
+			g.Assert(status.State).Equal("success")
+			g.Assert(status.Context).Equal("approvals/lgtm")
+		})
+
+		g.It("Should fail the commit status when vetoed", func() {
+			err := gitea.SetStatus(fakeUser, fakeRepo, 1, &model.Status{Granted: 2, Required: 2, Vetoes: []string{"hubot"}})
+			g.Assert(err == nil).IsTrue()
+
+			status := new(Status)
+			json.Unmarshal(payloads[len(payloads)-1], status)
+			g.Assert(status.State).Equal("failure")
+			g.Assert(status.Desc).Equal("blocked by hubot")
+		})
+
+		g.It("Should parse a comment hook", func() {
+			req, _ := http.NewRequest("POST", "/hook", bytes.NewBufferString(fakeCommentHook))
+			req.Header.Set("X-Gitea-Event", "issue_comment")
+			hook, err := gitea.GetHook(req)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(hook.Repo.Slug).Equal("octocat/hello-world")
+			g.Assert(hook.Repo.Owner).Equal("octocat")
+			g.Assert(hook.Issue.Number).Equal(1)
+			g.Assert(hook.Issue.Author).Equal("octocat")
+			g.Assert(hook.Comment.Author).Equal("hubot")
+			g.Assert(hook.Comment.Body).Equal("LGTM")
+		})
+
+		g.It("Should parse a synchronized pull request hook", func() {
+			req, _ := http.NewRequest("POST", "/hook", bytes.NewBufferString(fakePullHook))
+			req.Header.Set("X-Gogs-Event", "pull_request")
+			hook, err := gitea.GetHook(req)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(hook.Issue.Number).Equal(1)
+			g.Assert(hook.Issue.Branch).Equal("master")
+			g.Assert(hook.Repo.Owner).Equal("octocat")
+		})
+
+		g.It("Should ignore other hooks", func() {
+			req, _ := http.NewRequest("POST", "/hook", bytes.NewBufferString("{}"))
+			req.Header.Set("X-Gitea-Event", "push")
+			hook, err := gitea.GetHook(req)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(hook == nil).IsTrue()
+		})
+	})
+}
+
+// fakeServer is a stand-in for the subset of the Gitea api
+// used by the remote driver.
+func fakeServer(w http.ResponseWriter, r *http.Request) {
+	switch r.Method + " " + r.URL.Path {
+	case "GET /api/v1/orgs/octocat/teams":
+		w.Write([]byte(`[{"id": 1, "name": "Owners"}, {"id": 2, "name": "Maintainers"}]`))
+	case "GET /api/v1/teams/1/members":
+		w.Write([]byte(`[{"id": 1, "login": "octocat"}]`))
+	case "GET /api/v1/teams/2/members":
+		w.Write([]byte(`[{"id": 2, "login": "hubot"}]`))
+	case "GET /api/v1/repos/octocat/hello-world":
+		w.Write([]byte(`{
+			"id": 1,
+			"name": "hello-world",
+			"full_name": "octocat/hello-world",
+			"html_url": "http://gitea.example.com/octocat/hello-world",
+			"default_branch": "master",
+			"owner": {"login": "octocat"},
+			"permissions": {"admin": false, "push": true, "pull": true}
+		}`))
+	case "GET /api/v1/repos/octocat/hello-world/hooks":
+		w.Write([]byte(`[]`))
+	case "POST /api/v1/repos/octocat/hello-world/hooks":
+		w.WriteHeader(201)
+		w.Write([]byte(`{}`))
+	case "GET /api/v1/repos/octocat/hello-world/branch_protections/master":
+		w.Write([]byte(`{"branch_name": "master", "enable_status_check": true, "status_check_contexts": ["ci/drone"]}`))
+	case "PATCH /api/v1/repos/octocat/hello-world/branch_protections/master":
+		w.Write([]byte(`{}`))
+	case "GET /api/v1/repos/octocat/hello-world/branches":
+		w.Write([]byte(`[{"name": "master"}, {"name": "release/1.0"}, {"name": "release/1.1"}]`))
+	case "POST /api/v1/repos/octocat/hello-world/branch_protections":
+		w.WriteHeader(201)
+		w.Write([]byte(`{}`))
+	case "GET /api/v1/repos/octocat/hello-world/pulls/1/files":
+		w.Write([]byte(`[
+			{"filename": "README.md", "status": "modified"},
+			{"filename": "docs/index.md", "previous_filename": "index.md", "status": "renamed"}
+		]`))
+	case "GET /api/v1/repos/octocat/hello-world/issues/1/comments":
+		w.Write([]byte(`[
+			{"body": "please review", "created_at": "2016-05-01T12:00:00Z", "user": {"login": "octocat"}},
+			{"body": "LGTM", "created_at": "2016-05-01T13:00:00Z", "user": {"login": "hubot"}}
+		]`))
+	case "GET /api/v1/repos/octocat/hello-world/pulls/1/reviews":
+		w.Write([]byte(`[
+			{"state": "APPROVED", "submitted_at": "2016-05-01T12:00:00Z", "user": {"login": "hubot"}},
+			{"state": "REQUEST_CHANGES", "submitted_at": "2016-05-01T13:00:00Z", "user": {"login": "spaceghost"}},
+			{"state": "APPROVED", "dismissed": true, "submitted_at": "2016-05-01T14:00:00Z", "user": {"login": "octocat"}}
+		]`))
+	case "GET /api/v1/repos/octocat/hello-world/pulls/1":
+		w.Write([]byte(`{"number": 1, "title": "Update README", "user": {"login": "octocat"}, "head": {"sha": "6104942438c14ec7bd21c6cd5bd995272b3faff6"}, "base": {"ref": "master", "sha": "553c2077f0edc3d5dc5d17262f6aa498e69d6f8e"}}`))
+	case "GET /api/v1/repos/octocat/hello-world/contents/MAINTAINERS":
+		if r.FormValue("ref") == "release/1.0" {
+			w.Write([]byte(`{"content": "b2N0b2NhdAo=", "encoding": "base64"}`))
+			return
+		}
+		w.Write([]byte(`{"content": "aHVib3QK", "encoding": "base64"}`))
+	case "GET /api/v1/orgs/octocat/members/hubot":
+		w.WriteHeader(204)
+	case "GET /api/v1/repos/octocat/hello-world/issues":
+		if r.FormValue("created_by") == "hubot" {
+			w.Write([]byte(`[
+				{"number": 2, "user": {"login": "hubot"}, "pull_request": {"merged": false}},
+				{"number": 3, "user": {"login": "hubot"}, "pull_request": {"merged": true}}
+			]`))
+			return
+		}
+		w.Write([]byte(`[{"number": 4, "user": {"login": "spaceghost"}, "pull_request": {"merged": false}}]`))
+	case "GET /api/v1/users/hubot":
+		w.Write([]byte(`{"id": 2, "login": "hubot"}`))
+	case "POST /api/v1/repos/octocat/hello-world/statuses/6104942438c14ec7bd21c6cd5bd995272b3faff6":
+		w.WriteHeader(201)
+		w.Write([]byte(`{}`))
+	default:
+		w.WriteHeader(404)
+		w.Write([]byte(`{"message": "Not Found"}`))
+	}
+}
+
+var (
+	fakeUser = &model.User{Login: "octocat", Token: "cfcd2084"}
+	fakeRepo = &model.Repo{Owner: "octocat", Name: "hello-world", Slug: "octocat/hello-world", Secret: "b9015b08"}
+)
+
+var fakeCommentHook = `{
+	"action": "created",
+	"is_pull": true,
+	"issue": {"number": 1, "title": "Update README", "user": {"login": "octocat"}},
+	"comment": {"body": "LGTM", "user": {"login": "hubot"}},
+	"repository": {"name": "hello-world", "full_name": "octocat/hello-world", "owner": {"login": "octocat"}}
+}`
+
+var fakePullHook = `{
+	"action": "synchronized",
+	"pull_request": {"number": 1, "title": "Update README", "user": {"login": "octocat"}, "base": {"ref": "master"}},
+	"repository": {"name": "hello-world", "full_name": "octocat/hello-world", "owner": {"username": "octocat"}}
+}`
@@ -0,0 +1,162 @@
+package gitea
+
+import "time"
+
+type User struct {
+	ID     int64  `json:"id"`
+	Login  string `json:"login"`
+	Avatar string `json:"avatar_url"`
+}
+
+type Org struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+	Avatar   string `json:"avatar_url"`
+}
+
+type Team struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+type Repo struct {
+	ID            int64  `json:"id"`
+	Name          string `json:"name"`
+	FullName      string `json:"full_name"`
+	HTMLURL       string `json:"html_url"`
+	Private       bool   `json:"private"`
+	DefaultBranch string `json:"default_branch"`
+	Owner         User   `json:"owner"`
+	Permissions   struct {
+		Admin bool `json:"admin"`
+		Push  bool `json:"push"`
+		Pull  bool `json:"pull"`
+	} `json:"permissions"`
+}
+
+type Hook struct {
+	ID     int64             `json:"id,omitempty"`
+	Type   string            `json:"type"`
+	Config map[string]string `json:"config"`
+	Events []string          `json:"events"`
+	Active bool              `json:"active"`
+}
+
+type Branch struct {
+	Name string `json:"name"`
+}
+
+type BranchProtection struct {
+	Branch         string   `json:"branch_name"`
+	EnableStatus   bool     `json:"enable_status_check"`
+	StatusContexts []string `json:"status_check_contexts"`
+}
+
+type Comment struct {
+	Body    string    `json:"body"`
+	Created time.Time `json:"created_at"`
+	User    User      `json:"user"`
+}
+
+type Review struct {
+	State     string    `json:"state"`
+	Dismissed bool      `json:"dismissed"`
+	Submitted time.Time `json:"submitted_at"`
+	User      User      `json:"user"`
+}
+
+type PullRequest struct {
+	Number  int       `json:"number"`
+	Title   string    `json:"title"`
+	Draft   bool      `json:"draft"`
+	Created time.Time `json:"created_at"`
+	User    User      `json:"user"`
+	Head    struct {
+		SHA string `json:"sha"`
+	} `json:"head"`
+	Base struct {
+		Ref string `json:"ref"`
+		SHA string `json:"sha"`
+	} `json:"base"`
+}
+
+type Issue struct {
+	Number      int  `json:"number"`
+	User        User `json:"user"`
+	PullRequest *struct {
+		Merged bool `json:"merged"`
+	} `json:"pull_request"`
+}
+
+type ChangedFile struct {
+	Filename         string `json:"filename"`
+	PreviousFilename string `json:"previous_filename"`
+}
+
+type Commit struct {
+	SHA    string `json:"sha"`
+	Commit struct {
+		Committer struct {
+			Date time.Time `json:"date"`
+		} `json:"committer"`
+	} `json:"commit"`
+}
+
+type Content struct {
+	Content  string `json:"content"`
+	Encoding string `json:"encoding"`
+}
+
+type Status struct {
+	State   string `json:"state"`
+	Context string `json:"context"`
+	Desc    string `json:"description"`
+}
+
+// hookOwner represents the repository owner in the hook payload.
+// Gogs only populates the username field.
+type hookOwner struct {
+	Login    string `json:"login"`
+	Username string `json:"username"`
+}
+
+func (o hookOwner) login() string {
+	if len(o.Login) != 0 {
+		return o.Login
+	}
+	return o.Username
+}
+
+// hookRepo represents the repository subset of the hook payload.
+type hookRepo struct {
+	Name     string    `json:"name"`
+	FullName string    `json:"full_name"`
+	Owner    hookOwner `json:"owner"`
+}
+
+// commentHook represents a subset of the issue_comment payload.
+type commentHook struct {
+	Action string `json:"action"`
+	IsPull bool   `json:"is_pull"`
+
+	Issue struct {
+		Number      int       `json:"number"`
+		Title       string    `json:"title"`
+		User        User      `json:"user"`
+		PullRequest *struct{} `json:"pull_request"`
+	} `json:"issue"`
+
+	Comment struct {
+		Body string `json:"body"`
+		User User   `json:"user"`
+	} `json:"comment"`
+
+	Repository hookRepo `json:"repository"`
+}
+
+// pullRequestHook represents a subset of the pull_request payload.
+type pullRequestHook struct {
+	Action      string      `json:"action"`
+	PullRequest PullRequest `json:"pull_request"`
+	Repository  hookRepo    `json:"repository"`
+}
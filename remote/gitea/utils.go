@@ -0,0 +1,69 @@
+package gitea
+
+import (
+	"net/url"
+	"strings"
+)
+
+// getHook is a heper function that retrieves a hook by
+// hostname. To do this, it will retrieve a list of all hooks
+// and iterate through the list.
+func getHook(client *Client, owner, name, rawurl string) (*Hook, error) {
+	hooks, err := client.Hooks(owner, name)
+	if err != nil {
+		return nil, err
+	}
+	newurl, err := url.Parse(rawurl)
+	if err != nil {
+		return nil, err
+	}
+	for _, hook := range hooks {
+		oldurl, err := url.Parse(hook.Config["url"])
+		if err != nil {
+			continue
+		}
+		if newurl.Host == oldurl.Host {
+			return hook, nil
+		}
+	}
+	return nil, nil
+}
+
+// appendContext is a helper function that appends the status
+// context to the list, if not already present.
+func appendContext(contexts []string, context string) []string {
+	for _, c := range contexts {
+		if c == context {
+			return contexts
+		}
+	}
+	return append(contexts, context)
+}
+
+// splitTeam is a helper function that splits an org/team pair into
+// the organization and lowercase team name. A team without an
+// organization refers to the maintainers team of the organization.
+func splitTeam(team string) (string, string) {
+	parts := strings.SplitN(team, "/", 2)
+	if len(parts) != 2 {
+		return team, "maintainers"
+	}
+	return parts[0], strings.ToLower(parts[1])
+}
+
+// protectBranch is a helper function that adds the approval status to
+// the required status checks of the branch, creating the protection
+// if necessary.
+func protectBranch(client *Client, owner, name, branch string) error {
+	protection, err := client.BranchProtection(owner, name, branch)
+	if err != nil {
+		return client.BranchProtectionCreate(owner, name, &BranchProtection{
+			Branch:         branch,
+			EnableStatus:   true,
+			StatusContexts: []string{context},
+		})
+	}
+	protection.EnableStatus = true
+	protection.StatusContexts = appendContext(protection.StatusContexts, context)
+	return client.BranchProtectionEdit(owner, name, protection)
+}
@@ -0,0 +1,184 @@
+package github
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/lgtmco/lgtm/model"
+)
+
+// GetAppUser returns a user authenticated with an installation access
+// token for the repository. Installation tokens are cached until shortly
+// before they expire.
+func (g *Github) GetAppUser(r *model.Repo) (*model.User, error) {
+	if g.AppID == 0 {
+		return nil, nil
+	}
+
+	slug := strings.ToLower(fmt.Sprintf("%s/%s", r.Owner, r.Name))
+	g.mu.Lock()
+	if g.installs == nil {
+		g.installs = map[string]int64{}
+		g.tokens = map[int64]*InstallationToken{}
+	}
+	if g.fetches == nil {
+		g.fetches = map[string]*tokenFetch{}
+	}
+	id, ok := g.installs[slug]
+	token, cached := g.tokens[id]
+	if ok && cached && time.Now().Add(time.Minute).Before(token.Expires) {
+		g.mu.Unlock()
+		return &model.User{Token: token.Token}, nil
+	}
+
+	// the token is fetched without holding the lock, so that a slow
+	// response does not stall the hooks of other repositories, while
+	// concurrent hooks of the repository wait for the same fetch.
+	fetch, pending := g.fetches[slug]
+	if !pending {
+		fetch = &tokenFetch{done: make(chan struct{})}
+		g.fetches[slug] = fetch
+	}
+	g.mu.Unlock()
+
+	if pending {
+		<-fetch.done
+	} else {
+		fetch.id, fetch.token, fetch.err = g.fetchToken(r, id, ok)
+		g.mu.Lock()
+		delete(g.fetches, slug)
+		if fetch.err == nil {
+			g.installs[slug] = fetch.id
+			g.tokens[fetch.id] = fetch.token
+		}
+		g.mu.Unlock()
+		close(fetch.done)
+	}
+	if fetch.err != nil {
+		return nil, fetch.err
+	}
+	return &model.User{Token: fetch.token.Token}, nil
+}
+
+// tokenFetch is an installation token request in progress, which
+// is done when the channel is closed.
+type tokenFetch struct {
+	done  chan struct{}
+	id    int64
+	token *InstallationToken
+	err   error
+}
+
+// fetchToken is a helper function that creates an installation token
+// for the repository, looking up the installation when it is unknown.
+func (g *Github) fetchToken(r *model.Repo, id int64, known bool) (int64, *InstallationToken, error) {
+	signed, err := g.appToken()
+	if err != nil {
+		return 0, nil, fmt.Errorf("Error signing app token. %s", err)
+	}
+	client := NewClientToken(g.API, signed)
+	if !known {
+		installation, err := client.Installation(r.Owner, r.Name)
+		if err != nil {
+			return 0, nil, fmt.Errorf("Error fetching app installation. %s", err)
+		}
+		id = installation.ID
+	}
+	token, err := client.InstallationToken(id)
+	if err != nil {
+		return 0, nil, fmt.Errorf("Error creating installation token. %s", err)
+	}
+	return id, token, nil
+}
+
+// SetInstallation processes the installation events delivered to the
+// app webhook, updating the cached installations accordingly.
+func (g *Github) SetInstallation(r *http.Request) error {
+	if g.AppID == 0 {
+		return nil
+	}
+
+	payload, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	r.Body = ioutil.NopCloser(bytes.NewBuffer(payload))
+
+	// installation events change the installation used for each
+	// repository, so unsigned events are always rejected.
+	if len(g.AppSecret) == 0 {
+		return fmt.Errorf("Missing app webhook secret")
+	}
+	mac := hmac.New(sha256.New, []byte(g.AppSecret))
+	mac.Write(payload)
+	want := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(r.Header.Get("X-Hub-Signature-256")), []byte(want)) {
+		return fmt.Errorf("Invalid app webhook signature")
+	}
+
+	event := r.Header.Get("X-GitHub-Event")
+	if event != "installation" && event != "installation_repositories" {
+		return nil
+	}
+	data := installationHook{}
+	err = json.Unmarshal(payload, &data)
+	if err != nil {
+		return err
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.installs == nil {
+		g.installs = map[string]int64{}
+		g.tokens = map[int64]*InstallationToken{}
+	}
+
+	id := data.Installation.ID
+	switch data.Action {
+	case "deleted", "suspend", "new_permissions_accepted":
+		// the installation tokens are no longer valid, or lack
+		// the new permissions, and must be requested again.
+		delete(g.tokens, id)
+		if data.Action == "new_permissions_accepted" {
+			break
+		}
+		for slug, install := range g.installs {
+			if install == id {
+				delete(g.installs, slug)
+			}
+		}
+	case "created", "unsuspend":
+		for _, repo := range data.Repositories {
+			g.installs[strings.ToLower(repo.FullName)] = id
+		}
+	case "added", "removed":
+		for _, repo := range data.Added {
+			g.installs[strings.ToLower(repo.FullName)] = id
+		}
+		for _, repo := range data.Removed {
+			delete(g.installs, strings.ToLower(repo.FullName))
+		}
+	}
+	return nil
+}
+
+// appToken is a helper function that returns a JWT signed with the
+// app private key, used to authenticate as the app itself.
+func (g *Github) appToken() (string, error) {
+	now := time.Now()
+	token := jwt.New(jwt.SigningMethodRS256)
+	token.Claims["iat"] = now.Add(-time.Minute).Unix()
+	token.Claims["exp"] = now.Add(time.Minute * 9).Unix()
+	token.Claims["iss"] = g.AppID
+	return token.SignedString(g.AppKey)
+}
@@ -0,0 +1,134 @@
+package github
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lgtmco/lgtm/model"
+
+	"github.com/franela/goblin"
+)
+
+func TestApp(t *testing.T) {
+
+	var server *httptest.Server
+	var requests []*http.Request
+	var delay time.Duration
+
+	g := goblin.Goblin(t)
+	g.Describe("GitHub App", func() {
+
+		var github *Github
+		var key *rsa.PrivateKey
+
+		g.Before(func() {
+			key, _ = rsa.GenerateKey(rand.Reader, 1024)
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests = append(requests, r)
+				switch r.Method + " " + r.URL.Path {
+				case "GET /repos/octocat/hello-world/installation":
+					w.Write([]byte(`{"id": 42}`))
+				case "POST /app/installations/42/access_tokens":
+					time.Sleep(delay)
+					w.WriteHeader(201)
+					w.Write([]byte(`{"token": "v1.1f699f1069f60xxx", "expires_at": "` +
+						time.Now().Add(time.Hour).Format(time.RFC3339) + `"}`))
+				default:
+					w.WriteHeader(404)
+				}
+			}))
+		})
+		g.After(func() {
+			server.Close()
+		})
+		g.BeforeEach(func() {
+			requests = nil
+			delay = 0
+			github = &Github{
+				API:       server.URL + "/",
+				AppID:     1,
+				AppKey:    key,
+				AppSecret: "b9015b08",
+			}
+		})
+
+		g.It("Should return nil when not configured", func() {
+			github.AppID = 0
+			user, err := github.GetAppUser(fakeRepo)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(user == nil).IsTrue()
+			g.Assert(len(requests)).Equal(0)
+		})
+
+		g.It("Should exchange and cache the installation token", func() {
+			user, err := github.GetAppUser(fakeRepo)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(user.Token).Equal("v1.1f699f1069f60xxx")
+			g.Assert(requests[0].Header.Get("Authorization") != "").IsTrue()
+
+			user, err = github.GetAppUser(fakeRepo)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(user.Token).Equal("v1.1f699f1069f60xxx")
+			g.Assert(len(requests)).Equal(2)
+		})
+
+		g.It("Should exchange the installation token once for concurrent hooks", func() {
+			delay = 50 * time.Millisecond
+			var wg sync.WaitGroup
+			for i := 0; i < 3; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					github.GetAppUser(fakeRepo)
+				}()
+			}
+			wg.Wait()
+			g.Assert(len(requests)).Equal(2)
+		})
+
+		g.It("Should evict the cache when the app is uninstalled", func() {
+			github.GetAppUser(fakeRepo)
+
+			payload := `{"action": "deleted", "installation": {"id": 42}}`
+			req, _ := http.NewRequest("POST", "/hook/app", bytes.NewBufferString(payload))
+			req.Header.Set("X-GitHub-Event", "installation")
+			req.Header.Set("X-Hub-Signature-256", signApp(payload, github.AppSecret))
+			g.Assert(github.SetInstallation(req) == nil).IsTrue()
+
+			github.GetAppUser(fakeRepo)
+			g.Assert(len(requests)).Equal(4)
+		})
+
+		g.It("Should reject an unsigned installation event", func() {
+			req, _ := http.NewRequest("POST", "/hook/app", bytes.NewBufferString(`{}`))
+			req.Header.Set("X-GitHub-Event", "installation")
+			g.Assert(github.SetInstallation(req) != nil).IsTrue()
+		})
+
+		g.It("Should reject installation events without a secret", func() {
+			github.AppSecret = ""
+			req, _ := http.NewRequest("POST", "/hook/app", bytes.NewBufferString(`{}`))
+			req.Header.Set("X-GitHub-Event", "installation")
+			req.Header.Set("X-Hub-Signature-256", signApp(`{}`, ""))
+			g.Assert(github.SetInstallation(req) != nil).IsTrue()
+		})
+	})
+}
+
+// signApp is a helper function that signs the app webhook payload.
+func signApp(payload, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(payload))
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+var fakeRepo = &model.Repo{Owner: "octocat", Name: "hello-world", Slug: "octocat/hello-world"}
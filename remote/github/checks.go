@@ -0,0 +1,95 @@
+package github
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/lgtmco/lgtm/model"
+)
+
+// setCheckRun creates or updates the approval check run for the
+// commit. The check run is completed once the pull request is
+// approved or vetoed, and otherwise remains in progress.
+func (g *Github) setCheckRun(u *model.User, r *model.Repo, sha, name, title string, s *model.Status) error {
+	client := NewClientToken(g.API, u.Token)
+
+	check := &CheckRun{
+		Name:       name,
+		HeadSHA:    sha,
+		Status:     "in_progress",
+		DetailsURL: s.Link,
+		Output: CheckRunOutput{
+			Title:   title,
+			Summary: checkSummary(s),
+		},
+	}
+	switch {
+	case s.WorkInProgress:
+		// a work in progress remains in progress, even
+		// when vetoed, until it is ready for review.
+	case s.IsVetoed():
+		check.Status = "completed"
+		check.Conclusion = "failure"
+	case s.IsApproved():
+		check.Status = "completed"
+		check.Conclusion = "success"
+	}
+
+	runs, err := client.CheckRuns(r.Owner, r.Name, sha, name)
+	if err != nil {
+		return err
+	}
+	if len(runs) == 0 {
+		return client.CheckRunCreate(r.Owner, r.Name, check)
+	}
+	return client.CheckRunUpdate(r.Owner, r.Name, runs[0].ID, check)
+}
+
+// checkSummary is a helper function that renders the approval
+// status as the markdown summary of the check run.
+func checkSummary(s *model.Status) string {
+	var buf bytes.Buffer
+	if s.WorkInProgress {
+		fmt.Fprintf(&buf, "This pull request is a **work in progress**.\n\n")
+	}
+	fmt.Fprintf(&buf, "**%d of %d** required approvals granted.\n\n", s.Granted, s.Required)
+	if len(s.Quotas) != 0 {
+		fmt.Fprintf(&buf, "Approvals by org: %s\n\n", s.Summary())
+	}
+	if !s.WindowEnds.IsZero() {
+		fmt.Fprintf(&buf, "Review window ends at %s.\n\n", s.WindowEnds.UTC().Format("2006-01-02 15:04 MST"))
+	}
+	if len(s.Rule) != 0 {
+		fmt.Fprintf(&buf, "Rule: %s\n\n", s.Rule)
+	}
+	if len(s.Overrides) != 0 {
+		fmt.Fprintf(&buf, "Server policy: %s\n\n", strings.Join(s.Overrides, "; "))
+	}
+	if len(s.Approvers) != 0 {
+		fmt.Fprintf(&buf, "Approved by: %s\n\n", mentions(s.Approvers))
+	}
+	if len(s.Vetoes) != 0 {
+		fmt.Fprintf(&buf, "Blocked by: %s\n\n", mentions(s.Vetoes))
+	}
+	if len(s.Blockers) != 0 {
+		fmt.Fprintf(&buf, "Changes requested by: %s\n\n", mentions(s.Blockers))
+	}
+	if len(s.Pending) != 0 {
+		fmt.Fprintf(&buf, "Awaiting response from: %s\n\n", mentions(s.Pending))
+	}
+	if len(s.Link) != 0 {
+		fmt.Fprintf(&buf, "[View in LGTM](%s)\n", s.Link)
+	}
+	return buf.String()
+}
+
+// mentions is a helper function that formats the logins as a
+// comma separated list of mentions.
+func mentions(logins []string) string {
+	var out []string
+	for _, login := range logins {
+		out = append(out, "@"+login)
+	}
+	return strings.Join(out, ", ")
+}
@@ -0,0 +1,140 @@
+package github
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/lgtmco/lgtm/model"
+
+	"github.com/franela/goblin"
+)
+
+func TestChecks(t *testing.T) {
+
+	var server *httptest.Server
+	var requests []*http.Request
+	var payloads [][]byte
+	var existing string
+
+	g := goblin.Goblin(t)
+	g.Describe("Check runs", func() {
+
+		var github *Github
+
+		g.Before(func() {
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := ioutil.ReadAll(r.Body)
+				requests = append(requests, r)
+				payloads = append(payloads, body)
+				switch r.Method + " " + r.URL.Path {
+				case "GET /repos/octocat/hello-world/pulls/1":
+					w.Write([]byte(`{"number": 1, "head": {"sha": "6dcb09b5b57875f334f61aebed695e2e4193db5e"}}`))
+				case "GET /repos/octocat/hello-world/pulls/2":
+					w.Write([]byte(`{"number": 2, "title": "add feature", "draft": true, "user": {"login": "octocat"}, "base": {"ref": "master", "sha": "e5bd3914e2e596debea16f433f57875b5b90bcd6"}}`))
+				case "GET /repos/octocat/hello-world/commits/6dcb09b5b57875f334f61aebed695e2e4193db5e/check-runs":
+					w.Write([]byte(`{"check_runs": [` + existing + `]}`))
+				case "POST /repos/octocat/hello-world/check-runs":
+					w.WriteHeader(201)
+					w.Write([]byte(`{}`))
+				case "POST /repos/octocat/hello-world/statuses/6dcb09b5b57875f334f61aebed695e2e4193db5e":
+					w.WriteHeader(201)
+					w.Write([]byte(`{}`))
+				case "PATCH /repos/octocat/hello-world/check-runs/4":
+					w.Write([]byte(`{}`))
+				default:
+					w.WriteHeader(404)
+				}
+			}))
+		})
+		g.After(func() {
+			server.Close()
+		})
+		g.BeforeEach(func() {
+			requests = nil
+			payloads = nil
+			existing = ""
+			github = &Github{API: server.URL + "/", Checks: true, AppID: 1}
+		})
+
+		g.It("Should create an in progress check run", func() {
+			err := github.SetStatus(fakeUser, fakeRepo, 1, &model.Status{Granted: 1, Required: 2})
+			g.Assert(err == nil).IsTrue()
+
+			last := requests[len(requests)-1]
+			g.Assert(last.Method).Equal("POST")
+
+			check := new(CheckRun)
+			json.Unmarshal(payloads[len(payloads)-1], check)
+			g.Assert(check.Name).Equal("approvals/lgtm")
+			g.Assert(check.HeadSHA).Equal("6dcb09b5b57875f334f61aebed695e2e4193db5e")
+			g.Assert(check.Status).Equal("in_progress")
+			g.Assert(check.Output.Title).Equal("1 of 2 required approvals granted")
+		})
+
+		g.It("Should set the commit status without the app", func() {
+			github.AppID = 0
+			err := github.SetStatus(fakeUser, fakeRepo, 1, &model.Status{Granted: 1, Required: 2})
+			g.Assert(err == nil).IsTrue()
+
+			last := requests[len(requests)-1]
+			g.Assert(last.URL.Path).Equal("/repos/octocat/hello-world/statuses/6dcb09b5b57875f334f61aebed695e2e4193db5e")
+		})
+
+		g.It("Should complete an existing check run", func() {
+			existing = `{"id": 4, "name": "approvals/lgtm"}`
+			err := github.SetStatus(fakeUser, fakeRepo, 1, &model.Status{Granted: 2, Required: 2})
+			g.Assert(err == nil).IsTrue()
+
+			last := requests[len(requests)-1]
+			g.Assert(last.Method).Equal("PATCH")
+
+			check := new(CheckRun)
+			json.Unmarshal(payloads[len(payloads)-1], check)
+			g.Assert(check.Status).Equal("completed")
+			g.Assert(check.Conclusion).Equal("success")
+		})
+
+		g.It("Should keep a work in progress check run in progress", func() {
+			err := github.SetStatus(fakeUser, fakeRepo, 1, &model.Status{Granted: 2, Required: 2, WorkInProgress: true})
+			g.Assert(err == nil).IsTrue()
+
+			check := new(CheckRun)
+			json.Unmarshal(payloads[len(payloads)-1], check)
+			g.Assert(check.Status).Equal("in_progress")
+			g.Assert(check.Conclusion).Equal("")
+			g.Assert(check.Output.Title).Equal("work in progress")
+		})
+
+		g.It("Should get a draft pull request", func() {
+			issue, err := github.GetIssue(fakeUser, fakeRepo, 2)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(issue.Draft).IsTrue()
+			g.Assert(issue.Title).Equal("add feature")
+			g.Assert(issue.Base).Equal("e5bd3914e2e596debea16f433f57875b5b90bcd6")
+		})
+
+		g.It("Should summarize the approvals", func() {
+			summary := checkSummary(&model.Status{
+				Granted:   1,
+				Required:  2,
+				Approvers: []string{"bradrydzewski"},
+				Blockers:  []string{"mattnorris"},
+				Pending:   []string{"janedoe", "johnsmith"},
+				Rule:      "2 approvals from MAINTAINERS matching (?i)LGTM",
+				Link:      "https://lgtm.example.com/octocat",
+			})
+			g.Assert(strings.Contains(summary, "**1 of 2** required approvals granted")).IsTrue()
+			g.Assert(strings.Contains(summary, "Rule: 2 approvals from MAINTAINERS matching (?i)LGTM")).IsTrue()
+			g.Assert(strings.Contains(summary, "Approved by: @bradrydzewski")).IsTrue()
+			g.Assert(strings.Contains(summary, "Changes requested by: @mattnorris")).IsTrue()
+			g.Assert(strings.Contains(summary, "Awaiting response from: @janedoe, @johnsmith")).IsTrue()
+			g.Assert(strings.Contains(summary, "[View in LGTM](https://lgtm.example.com/octocat)")).IsTrue()
+		})
+	})
+}
+
+var fakeUser = &model.User{Login: "octocat", Token: "cfcd2084"}
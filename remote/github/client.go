@@ -8,17 +8,25 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"strings"
 
 	"golang.org/x/oauth2"
 )
 
 const (
-	pathLogin  = "%slogin?access_token=%s"
-	pathUser   = "%sapi/user"
-	pathRepos  = "%sapi/user/repos"
-	pathRepo   = "%sapi/repos/%s"
-	pathConf   = "%sapi/repos/%s/maintainers"
-	pathBranch = "%srepos/%s/%s/branches/%s"
+	pathLogin   = "%slogin?access_token=%s"
+	pathUser    = "%sapi/user"
+	pathRepos   = "%sapi/user/repos"
+	pathRepo    = "%sapi/repos/%s"
+	pathConf    = "%sapi/repos/%s/maintainers"
+	pathBranch  = "%srepos/%s/%s/branches/%s"
+	pathPull    = "%srepos/%s/%s/pulls/%d"
+	pathReview  = "%srepos/%s/%s/pulls/%d/reviews?per_page=100"
+	pathInst    = "%srepos/%s/%s/installation"
+	pathChecks  = "%srepos/%s/%s/commits/%s/check-runs?check_name=%s"
+	pathCheck   = "%srepos/%s/%s/check-runs"
+	pathCheckID = "%srepos/%s/%s/check-runs/%d"
+	pathToken   = "%sapp/installations/%d/access_tokens"
 )
 
 type Client struct {
@@ -58,6 +66,68 @@ func (c *Client) BranchProtect(owner, name, branch string, in *Branch) error {
 	return c.patch(uri, in, nil)
 }
 
+// PullRequest returns the pull request, including the draft
+// state that is not supported by the go-github client.
+func (c *Client) PullRequest(owner, name string, num int) (*PullRequest, error) {
+	out := new(PullRequest)
+	uri := fmt.Sprintf(pathPull, c.base, owner, name, num)
+	err := c.get(uri, out)
+	return out, err
+}
+
+// Reviews returns the pull request reviews, following the
+// pagination links until every page is read.
+func (c *Client) Reviews(owner, name string, num int) ([]*Review, error) {
+	out := []*Review{}
+	uri := fmt.Sprintf(pathReview, c.base, owner, name, num)
+	for len(uri) != 0 {
+		page := []*Review{}
+		next, err := c.page(uri, &page)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, page...)
+		uri = next
+	}
+	return out, nil
+}
+
+// CheckRuns returns the named check runs for the commit.
+func (c *Client) CheckRuns(owner, name, sha, check string) ([]*CheckRun, error) {
+	out := new(CheckRuns)
+	uri := fmt.Sprintf(pathChecks, c.base, owner, name, sha, url.QueryEscape(check))
+	err := c.get(uri, out)
+	return out.CheckRuns, err
+}
+
+func (c *Client) CheckRunCreate(owner, name string, in *CheckRun) error {
+	uri := fmt.Sprintf(pathCheck, c.base, owner, name)
+	return c.post(uri, in, nil)
+}
+
+func (c *Client) CheckRunUpdate(owner, name string, id int64, in *CheckRun) error {
+	uri := fmt.Sprintf(pathCheckID, c.base, owner, name, id)
+	return c.patch(uri, in, nil)
+}
+
+// Installation returns the app installation for the repository. The
+// client must be authenticated with the app JWT.
+func (c *Client) Installation(owner, name string) (*Installation, error) {
+	out := new(Installation)
+	uri := fmt.Sprintf(pathInst, c.base, owner, name)
+	err := c.get(uri, out)
+	return out, err
+}
+
+// InstallationToken creates an installation access token. The
+// client must be authenticated with the app JWT.
+func (c *Client) InstallationToken(id int64) (*InstallationToken, error) {
+	out := new(InstallationToken)
+	uri := fmt.Sprintf(pathToken, c.base, id)
+	err := c.post(uri, nil, out)
+	return out, err
+}
+
 //
 // http request helper functions
 //
@@ -67,6 +137,23 @@ func (c *Client) get(rawurl string, out interface{}) error {
 	return c.do(rawurl, "GET", nil, out)
 }
 
+// helper function for making an http GET request of a list, returning
+// the url of the next page from the Link header, or an empty string
+// for the last page.
+func (c *Client) page(rawurl string, out interface{}) (string, error) {
+	resp, err := c.send(rawurl, "GET", nil)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	err = json.NewDecoder(resp.Body).Decode(out)
+	if err != nil {
+		return "", err
+	}
+	return nextPage(resp.Header.Get("Link")), nil
+}
+
 // helper function for making an http POST request.
 func (c *Client) post(rawurl string, in, out interface{}) error {
 	return c.do(rawurl, "POST", in, out)
@@ -107,6 +194,16 @@ func (c *Client) do(rawurl, method string, in, out interface{}) error {
 
 // helper function to stream an http request
 func (c *Client) stream(rawurl, method string, in, out interface{}) (io.ReadCloser, error) {
+	resp, err := c.send(rawurl, method, in)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// helper function to send an http request, returning the response
+// when the request succeeds.
+func (c *Client) send(rawurl, method string, in interface{}) (*http.Response, error) {
 	uri, err := url.Parse(rawurl)
 	if err != nil {
 		return nil, err
@@ -140,7 +237,28 @@ func (c *Client) stream(rawurl, method string, in, out interface{}) (io.ReadClos
 	if resp.StatusCode > http.StatusPartialContent {
 		defer resp.Body.Close()
 		out, _ := ioutil.ReadAll(resp.Body)
-		return nil, fmt.Errorf(string(out))
+		return nil, fmt.Errorf("%s", out)
 	}
-	return resp.Body, nil
+	return resp, nil
+}
+
+// nextPage is a helper function that returns the url of the next
+// page from the Link header of a list response, for example:
+//
+//	<https://api.github.com/repositories/1/pulls/1/reviews?page=2>; rel="next"
+func nextPage(link string) string {
+	for _, part := range strings.Split(link, ",") {
+		segments := strings.Split(part, ";")
+		if len(segments) < 2 {
+			continue
+		}
+		for _, param := range segments[1:] {
+			if strings.TrimSpace(param) != `rel="next"` {
+				continue
+			}
+			uri := strings.TrimSpace(segments[0])
+			return strings.TrimSuffix(strings.TrimPrefix(uri, "<"), ">")
+		}
+	}
+	return ""
 }
@@ -0,0 +1,69 @@
+package github
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lgtmco/lgtm/model"
+
+	"github.com/franela/goblin"
+)
+
+func TestClient(t *testing.T) {
+
+	var server *httptest.Server
+
+	g := goblin.Goblin(t)
+	g.Describe("GitHub client", func() {
+
+		g.Before(func() {
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path + "?page=" + r.FormValue("page") {
+				case "/repos/octocat/hello-world/pulls/1/reviews?page=":
+					w.Header().Set("Link", fmt.Sprintf(`<%s/repos/octocat/hello-world/pulls/1/reviews?per_page=100&page=2>; rel="next", <%s/repos/octocat/hello-world/pulls/1/reviews?per_page=100&page=2>; rel="last"`, server.URL, server.URL))
+					w.Write([]byte(`[{"state": "APPROVED", "user": {"login": "hubot"}}]`))
+				case "/repos/octocat/hello-world/pulls/1/reviews?page=2":
+					w.Header().Set("Link", fmt.Sprintf(`<%s/repos/octocat/hello-world/pulls/1/reviews?per_page=100&page=1>; rel="first"`, server.URL))
+					w.Write([]byte(`[{"state": "CHANGES_REQUESTED", "user": {"login": "spaceghost"}}]`))
+				case "/repos/octocat/hello-world/issues/1/comments?page=":
+					w.Header().Set("Link", fmt.Sprintf(`<%s/repos/octocat/hello-world/issues/1/comments?per_page=100&page=2>; rel="next"`, server.URL))
+					w.Write([]byte(`[{"body": "lgtm", "user": {"login": "hubot"}, "created_at": "2016-01-01T00:00:00Z"}]`))
+				case "/repos/octocat/hello-world/issues/1/comments?page=2":
+					w.Write([]byte(`[{"body": "un-lgtm", "user": {"login": "hubot"}, "created_at": "2016-01-02T00:00:00Z"}]`))
+				default:
+					w.WriteHeader(404)
+				}
+			}))
+		})
+		g.After(func() {
+			server.Close()
+		})
+
+		g.It("Should get the reviews of every page", func() {
+			client := NewClient(server.URL + "/")
+			reviews, err := client.Reviews("octocat", "hello-world", 1)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(len(reviews)).Equal(2)
+			g.Assert(reviews[0].User.Login).Equal("hubot")
+			g.Assert(reviews[1].User.Login).Equal("spaceghost")
+		})
+
+		g.It("Should get the comments of every page", func() {
+			github := &Github{API: server.URL + "/"}
+			repo := &model.Repo{Owner: "octocat", Name: "hello-world"}
+			comments, err := github.GetComments(&model.User{}, repo, 1)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(len(comments)).Equal(2)
+			g.Assert(comments[0].Body).Equal("lgtm")
+			g.Assert(comments[1].Body).Equal("un-lgtm")
+		})
+
+		g.It("Should parse the next page link", func() {
+			g.Assert(nextPage(`<https://api.github.com/x?page=2>; rel="next", <https://api.github.com/x?page=5>; rel="last"`)).Equal("https://api.github.com/x?page=2")
+			g.Assert(nextPage(`<https://api.github.com/x?page=1>; rel="prev"`)).Equal("")
+			g.Assert(nextPage("")).Equal("")
+		})
+	})
+}
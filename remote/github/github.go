@@ -1,10 +1,12 @@
 package github
 
 import (
+	"crypto/rsa"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
 	log "github.com/Sirupsen/logrus"
@@ -23,6 +25,24 @@ type Github struct {
 	Client string
 	Secret string
 	Scopes []string
+
+	// AppID, AppKey and AppSecret configure the GitHub App used to
+	// authenticate hook processing in place of the user that activated
+	// the repository. When AppID is zero the user token is used. The
+	// AppSecret signing the app webhook deliveries is required.
+	AppID     int64
+	AppKey    *rsa.PrivateKey
+	AppSecret string
+
+	// Checks publishes the approval status as a check run with a
+	// summary of the approvals, instead of a commit status. Check
+	// runs require the GitHub App.
+	Checks bool
+
+	mu       sync.Mutex
+	installs map[string]int64
+	tokens   map[int64]*InstallationToken
+	fetches  map[string]*tokenFetch
 }
 
 func (g *Github) GetUser(res http.ResponseWriter, req *http.Request) (*model.User, error) {
@@ -93,15 +113,27 @@ func (g *Github) GetTeams(user *model.User) ([]*model.Team, error) {
 	return teams, nil
 }
 
+func (g *Github) GetMember(user *model.User, login string) (*model.Member, error) {
+	client := setupClient(g.API, user.Token)
+	account, _, err := client.Users.Get(login)
+	if err != nil {
+		return nil, err
+	}
+	return &model.Member{
+		Login: *account.Login,
+	}, nil
+}
+
 func (g *Github) GetMembers(user *model.User, team string) ([]*model.Member, error) {
+	org, name := splitTeam(team)
 	client := setupClient(g.API, user.Token)
-	teams, _, err := client.Organizations.ListTeams(team, &github.ListOptions{PerPage: 100})
+	teams, _, err := client.Organizations.ListTeams(org, &github.ListOptions{PerPage: 100})
 	if err != nil {
 		return nil, fmt.Errorf("Error accessing team list. %s", err)
 	}
 	var id int
 	for _, team := range teams {
-		if strings.ToLower(*team.Name) == "maintainers" {
+		if strings.ToLower(*team.Name) == name || (team.Slug != nil && *team.Slug == name) {
 			id = *team.ID
 			break
 		}
@@ -190,19 +222,14 @@ func (g *Github) SetHook(user *model.User, repo *model.Repo, link string) error
 		client.Repositories.DeleteHook(repo.Owner, repo.Name, *old.ID)
 	}
 
-	_, err = CreateHook(client, repo.Owner, repo.Name, link)
+	_, err = CreateHook(client, repo.Owner, repo.Name, link, repo.Secret)
 	if err != nil {
 		log.Debugf("Error creating the webhook at %s. %s", link, err)
 		return err
 	}
 
-	in := new(Branch)
-	in.Protection.Enabled = true
-	in.Protection.Checks.Enforcement = "non_admins"
-	in.Protection.Checks.Contexts = []string{context}
-
 	client_ := NewClientToken(g.API, user.Token)
-	err = client_.BranchProtect(repo.Owner, repo.Name, *repo_.DefaultBranch, in)
+	err = client_.BranchProtect(repo.Owner, repo.Name, *repo_.DefaultBranch, protection())
 	if err != nil {
 		if g.URL == "https://github.com" {
 			return err
@@ -212,6 +239,33 @@ func (g *Github) SetHook(user *model.User, repo *model.Repo, link string) error
 	return nil
 }
 
+func (g *Github) SetBranches(user *model.User, repo *model.Repo, patterns []string) error {
+	client := setupClient(g.API, user.Token)
+	client_ := NewClientToken(g.API, user.Token)
+
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		branches, resp, err := client.Repositories.ListBranches(repo.Owner, repo.Name, opts)
+		if err != nil {
+			return fmt.Errorf("Error fetching branches. %s", err)
+		}
+		for _, branch := range branches {
+			if !model.MatchBranch(patterns, *branch.Name) {
+				continue
+			}
+			err = client_.BranchProtect(repo.Owner, repo.Name, *branch.Name, protection())
+			if err != nil {
+				return fmt.Errorf("Error configuring protected branch %s. %s", *branch.Name, err)
+			}
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	return nil
+}
+
 func (g *Github) DelHook(user *model.User, repo *model.Repo, link string) error {
 	client := setupClient(g.API, user.Token)
 
@@ -249,32 +303,134 @@ func (g *Github) DelHook(user *model.User, repo *model.Repo, link string) error
 func (g *Github) GetComments(u *model.User, r *model.Repo, num int) ([]*model.Comment, error) {
 	client := setupClient(g.API, u.Token)
 
-	opts := github.IssueListCommentsOptions{Direction: "desc", Sort: "created"}
+	// the issue comments are listed oldest first whatever the sort
+	// options, so every page is read and the caller sorts them.
+	opts := github.IssueListCommentsOptions{}
 	opts.PerPage = 100
-	comments_, _, err := client.Issues.ListComments(r.Owner, r.Name, num, &opts)
+	comments := []*model.Comment{}
+	for {
+		comments_, resp, err := client.Issues.ListComments(r.Owner, r.Name, num, &opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, comment := range comments_ {
+			comments = append(comments, &model.Comment{
+				Author:  *comment.User.Login,
+				Body:    *comment.Body,
+				Created: *comment.CreatedAt,
+			})
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	return comments, nil
+}
+
+func (g *Github) GetReviews(u *model.User, r *model.Repo, num int) ([]*model.Review, error) {
+	client := NewClientToken(g.API, u.Token)
+
+	reviews_, err := client.Reviews(r.Owner, r.Name, num)
 	if err != nil {
 		return nil, err
 	}
-	comments := []*model.Comment{}
-	for _, comment := range comments_ {
-		comments = append(comments, &model.Comment{
-			Author: *comment.User.Login,
-			Body:   *comment.Body,
+	reviews := []*model.Review{}
+	for _, review := range reviews_ {
+		reviews = append(reviews, &model.Review{
+			Author:  review.User.Login,
+			State:   review.State,
+			Created: review.Submitted,
 		})
 	}
-	return comments, nil
+	return reviews, nil
+}
+
+func (g *Github) GetContentsRef(u *model.User, r *model.Repo, path, ref string) ([]byte, error) {
+	client := setupClient(g.API, u.Token)
+	return GetFile(client, r.Owner, r.Name, path, ref)
 }
 
-func (g *Github) GetContents(u *model.User, r *model.Repo, path string) ([]byte, error) {
+func (g *Github) GetHead(u *model.User, r *model.Repo, num int) (*model.Commit, error) {
 	client := setupClient(g.API, u.Token)
-	content, _, _, err := client.Repositories.GetContents(r.Owner, r.Name, path, nil)
+
+	pr, _, err := client.PullRequests.Get(r.Owner, r.Name, num)
 	if err != nil {
 		return nil, err
 	}
-	return content.Decode()
+	commit, _, err := client.Git.GetCommit(r.Owner, r.Name, *pr.Head.SHA)
+	if err != nil {
+		return nil, err
+	}
+	return &model.Commit{
+		SHA:     *commit.SHA,
+		Created: *commit.Committer.Date,
+	}, nil
+}
+
+func (g *Github) GetIssue(u *model.User, r *model.Repo, num int) (*model.Issue, error) {
+	client := NewClientToken(g.API, u.Token)
+
+	pr, err := client.PullRequest(r.Owner, r.Name, num)
+	if err != nil {
+		return nil, err
+	}
+	return &model.Issue{
+		Number:  num,
+		Title:   pr.Title,
+		Author:  pr.User.Login,
+		Branch:  pr.Base.Ref,
+		Base:    pr.Base.SHA,
+		Draft:   pr.Draft,
+		Created: pr.Created,
+	}, nil
+}
+
+func (g *Github) GetAuthor(u *model.User, r *model.Repo, login string) (*model.Author, error) {
+	client := setupClient(g.API, u.Token)
+
+	author := &model.Author{Login: login}
+	if strings.EqualFold(r.Owner, login) {
+		author.Member = true
+	} else {
+		member, _, err := client.Organizations.IsMember(r.Owner, login)
+		if err != nil {
+			return nil, fmt.Errorf("Error fetching org membership. %s", err)
+		}
+		author.Member = member
+	}
+	opts := &github.CommitsListOptions{Author: login}
+	opts.PerPage = 1
+	commits, _, err := client.Repositories.ListCommits(r.Owner, r.Name, opts)
+	if err != nil {
+		return nil, fmt.Errorf("Error fetching commits. %s", err)
+	}
+	author.FirstTime = len(commits) == 0
+	return author, nil
+}
+
+func (g *Github) GetFiles(u *model.User, r *model.Repo, num int) ([]string, error) {
+	client := setupClient(g.API, u.Token)
+
+	var files []string
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		page, resp, err := client.PullRequests.ListFiles(r.Owner, r.Name, num, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range page {
+			files = append(files, *file.Filename)
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	return files, nil
 }
 
-func (g *Github) SetStatus(u *model.User, r *model.Repo, num, granted, required int) error {
+func (g *Github) SetStatus(u *model.User, r *model.Repo, num int, s *model.Status) error {
 	client := setupClient(g.API, u.Token)
 
 	pr, _, err := client.PullRequests.Get(r.Owner, r.Name, num)
@@ -285,13 +441,37 @@ func (g *Github) SetStatus(u *model.User, r *model.Repo, num, granted, required
 	status := "success"
 	desc := "this commit looks good"
 
-	if granted < required {
+	switch {
+	case s.WorkInProgress:
+		status = "pending"
+		desc = "work in progress"
+	case s.IsVetoed():
+		status = "failure"
+		desc = fmt.Sprintf("blocked by %s", strings.Join(s.Vetoes, ", "))
+	case len(s.Blockers) != 0:
 		status = "pending"
-		desc = fmt.Sprintf("%d of %d required approvals granted", granted, required)
+		desc = fmt.Sprintf("changes requested by %s", strings.Join(s.Blockers, ", "))
+	case s.Granted < s.Required:
+		status = "pending"
+		desc = s.Summary()
+	case !s.WindowEnds.IsZero():
+		status = "pending"
+		desc = s.WindowSummary()
+	}
+
+	name := context
+	if len(s.Context) != 0 {
+		name = s.Context
+	}
+
+	// check runs are only created with an installation token of a
+	// GitHub App, and otherwise the commit status is used.
+	if g.Checks && g.AppID != 0 {
+		return g.setCheckRun(u, r, *pr.Head.SHA, name, desc, s)
 	}
 
 	data := github.RepoStatus{
-		Context:     github.String(context),
+		Context:     github.String(name),
 		State:       github.String(status),
 		Description: github.String(desc),
 	}
@@ -301,12 +481,19 @@ func (g *Github) SetStatus(u *model.User, r *model.Repo, num, granted, required
 }
 
 func (g *Github) GetHook(r *http.Request) (*model.Hook, error) {
-
-	// only process comment hooks
-	if r.Header.Get("X-Github-Event") != "issue_comment" {
+	switch r.Header.Get("X-Github-Event") {
+	case "issue_comment":
+		return parseCommentHook(r)
+	case "pull_request":
+		return parsePullRequestHook(r)
+	case "pull_request_review":
+		return parseReviewHook(r)
+	default:
 		return nil, nil
 	}
+}
 
+func parseCommentHook(r *http.Request) (*model.Hook, error) {
 	data := commentHook{}
 	err := json.NewDecoder(r.Body).Decode(&data)
 	if err != nil {
@@ -331,3 +518,66 @@ func (g *Github) GetHook(r *http.Request) (*model.Hook, error) {
 
 	return hook, nil
 }
+
+func parsePullRequestHook(r *http.Request) (*model.Hook, error) {
+	data := pullRequestHook{}
+	err := json.NewDecoder(r.Body).Decode(&data)
+	if err != nil {
+		return nil, err
+	}
+
+	// opened and reopened pull requests need an approval status, and
+	// new commits pushed to the pull request, and changes to its title
+	// or draft state, require the approval status to be re-evaluated.
+	switch data.Action {
+	case "opened", "reopened", "synchronize", "edited", "ready_for_review", "converted_to_draft":
+	default:
+		return nil, nil
+	}
+
+	hook := new(model.Hook)
+	hook.Issue = new(model.Issue)
+	hook.Issue.Number = data.PullRequest.Number
+	hook.Issue.Title = data.PullRequest.Title
+	hook.Issue.Author = data.PullRequest.User.Login
+	hook.Issue.Branch = data.PullRequest.Base.Ref
+	hook.Issue.Base = data.PullRequest.Base.SHA
+	hook.Issue.Draft = data.PullRequest.Draft
+	hook.Issue.Created = data.PullRequest.Created
+	hook.Repo = new(model.Repo)
+	hook.Repo.Owner = data.Repository.Owner.Login
+	hook.Repo.Name = data.Repository.Name
+	hook.Repo.Slug = data.Repository.FullName
+
+	return hook, nil
+}
+
+func parseReviewHook(r *http.Request) (*model.Hook, error) {
+	data := reviewHook{}
+	err := json.NewDecoder(r.Body).Decode(&data)
+	if err != nil {
+		return nil, err
+	}
+
+	// submitted and dismissed reviews both change the
+	// approval status of the pull request.
+	if data.Action != "submitted" && data.Action != "dismissed" {
+		return nil, nil
+	}
+
+	hook := new(model.Hook)
+	hook.Issue = new(model.Issue)
+	hook.Issue.Number = data.PullRequest.Number
+	hook.Issue.Title = data.PullRequest.Title
+	hook.Issue.Author = data.PullRequest.User.Login
+	hook.Issue.Branch = data.PullRequest.Base.Ref
+	hook.Issue.Base = data.PullRequest.Base.SHA
+	hook.Issue.Draft = data.PullRequest.Draft
+	hook.Issue.Created = data.PullRequest.Created
+	hook.Repo = new(model.Repo)
+	hook.Repo.Owner = data.Repository.Owner.Login
+	hook.Repo.Name = data.Repository.Name
+	hook.Repo.Slug = data.Repository.FullName
+
+	return hook, nil
+}
@@ -0,0 +1,48 @@
+package github
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+
+	"github.com/franela/goblin"
+)
+
+func TestGithub(t *testing.T) {
+
+	g := goblin.Goblin(t)
+	g.Describe("GitHub hooks", func() {
+
+		g.It("Should parse an opened pull request hook", func() {
+			for _, action := range []string{"opened", "reopened"} {
+				req, _ := http.NewRequest("POST", "/hook", bytes.NewBufferString(`{"action": "`+action+`", `+fakePullRequest))
+				req.Header.Set("X-Github-Event", "pull_request")
+				hook, err := new(Github).GetHook(req)
+				g.Assert(err == nil).IsTrue()
+				g.Assert(hook.Issue.Number).Equal(1)
+				g.Assert(hook.Issue.Base).Equal("e5bd3914e2e596debea16f433f57875b5b90bcd6")
+				g.Assert(hook.Repo.Slug).Equal("octocat/hello-world")
+			}
+		})
+
+		g.It("Should ignore a closed pull request hook", func() {
+			req, _ := http.NewRequest("POST", "/hook", bytes.NewBufferString(`{"action": "closed", `+fakePullRequest))
+			req.Header.Set("X-Github-Event", "pull_request")
+			hook, err := new(Github).GetHook(req)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(hook == nil).IsTrue()
+		})
+	})
+}
+
+var fakePullRequest = `"pull_request": {
+	"number": 1,
+	"title": "add feature",
+	"user": {"login": "octocat"},
+	"base": {"ref": "master", "sha": "e5bd3914e2e596debea16f433f57875b5b90bcd6"}
+},
+"repository": {
+	"name": "hello-world",
+	"full_name": "octocat/hello-world",
+	"owner": {"login": "octocat"}
+}}`
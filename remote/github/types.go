@@ -1,5 +1,7 @@
 package github
 
+import "time"
+
 type Error struct {
 	Message string `json:"message"`
 }
@@ -17,6 +19,72 @@ type Branch struct {
 	} `json:"protection"`
 }
 
+type PullRequest struct {
+	Number  int       `json:"number"`
+	Title   string    `json:"title"`
+	Draft   bool      `json:"draft"`
+	Created time.Time `json:"created_at"`
+	User    struct {
+		Login string `json:"login"`
+	} `json:"user"`
+	Base struct {
+		Ref string `json:"ref"`
+		SHA string `json:"sha"`
+	} `json:"base"`
+}
+
+type Review struct {
+	State     string    `json:"state"`
+	Submitted time.Time `json:"submitted_at"`
+	User      struct {
+		Login string `json:"login"`
+	} `json:"user"`
+}
+
+type CheckRun struct {
+	ID         int64          `json:"id,omitempty"`
+	Name       string         `json:"name"`
+	HeadSHA    string         `json:"head_sha,omitempty"`
+	Status     string         `json:"status"`
+	Conclusion string         `json:"conclusion,omitempty"`
+	DetailsURL string         `json:"details_url,omitempty"`
+	Output     CheckRunOutput `json:"output"`
+}
+
+type CheckRunOutput struct {
+	Title   string `json:"title"`
+	Summary string `json:"summary"`
+}
+
+type CheckRuns struct {
+	CheckRuns []*CheckRun `json:"check_runs"`
+}
+
+type Installation struct {
+	ID int64 `json:"id"`
+}
+
+type InstallationToken struct {
+	Token   string    `json:"token"`
+	Expires time.Time `json:"expires_at"`
+}
+
+// installationHook represents a subset of the installation and
+// installation_repositories payloads.
+type installationHook struct {
+	Action       string       `json:"action"`
+	Installation Installation `json:"installation"`
+	Repositories []struct {
+		FullName string `json:"full_name"`
+	} `json:"repositories"`
+	Added []struct {
+		FullName string `json:"full_name"`
+	} `json:"repositories_added"`
+	Removed []struct {
+		FullName string `json:"full_name"`
+	} `json:"repositories_removed"`
+}
+
 // commentHook represents a subset of the issue_comment payload.
 type commentHook struct {
 	Issue struct {
@@ -50,3 +118,78 @@ type commentHook struct {
 		} `json:"owner"`
 	} `json:"repository"`
 }
+
+// pullRequestHook represents a subset of the pull_request payload.
+type pullRequestHook struct {
+	Action string `json:"action"`
+
+	PullRequest struct {
+		Link    string    `json:"html_url"`
+		Number  int       `json:"number"`
+		Title   string    `json:"title"`
+		Draft   bool      `json:"draft"`
+		Created time.Time `json:"created_at"`
+		User    struct {
+			Login string `json:"login"`
+		} `json:"user"`
+
+		Head struct {
+			SHA string `json:"sha"`
+		} `json:"head"`
+		Base struct {
+			Ref string `json:"ref"`
+			SHA string `json:"sha"`
+		} `json:"base"`
+	} `json:"pull_request"`
+
+	Repository struct {
+		Name     string `json:"name"`
+		FullName string `json:"full_name"`
+		Desc     string `json:"description"`
+		Private  bool   `json:"private"`
+		Owner    struct {
+			Login  string `json:"login"`
+			Type   string `json:"type"`
+			Avatar string `json:"avatar_url"`
+		} `json:"owner"`
+	} `json:"repository"`
+}
+
+// reviewHook represents a subset of the pull_request_review payload.
+type reviewHook struct {
+	Action string `json:"action"`
+
+	Review struct {
+		State string `json:"state"`
+		User  struct {
+			Login string `json:"login"`
+		} `json:"user"`
+	} `json:"review"`
+
+	PullRequest struct {
+		Link    string    `json:"html_url"`
+		Number  int       `json:"number"`
+		Title   string    `json:"title"`
+		Draft   bool      `json:"draft"`
+		Created time.Time `json:"created_at"`
+		User    struct {
+			Login string `json:"login"`
+		} `json:"user"`
+		Base struct {
+			Ref string `json:"ref"`
+			SHA string `json:"sha"`
+		} `json:"base"`
+	} `json:"pull_request"`
+
+	Repository struct {
+		Name     string `json:"name"`
+		FullName string `json:"full_name"`
+		Desc     string `json:"description"`
+		Private  bool   `json:"private"`
+		Owner    struct {
+			Login  string `json:"login"`
+			Type   string `json:"type"`
+			Avatar string `json:"avatar_url"`
+		} `json:"owner"`
+	} `json:"repository"`
+}
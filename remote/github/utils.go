@@ -3,6 +3,7 @@ package github
 import (
 	"fmt"
 	"net/url"
+	"strings"
 
 	"github.com/google/go-github/github"
 	"golang.org/x/oauth2"
@@ -60,14 +61,15 @@ func DeleteHook(client *github.Client, owner, name, url string) error {
 }
 
 // CreateHook is a heper function that creates a post-commit hook
-// for the specified repository.
-func CreateHook(client *github.Client, owner, name, url string) (*github.Hook, error) {
+// for the specified repository. Payloads are signed with the secret.
+func CreateHook(client *github.Client, owner, name, url, secret string) (*github.Hook, error) {
 	var hook = new(github.Hook)
 	hook.Name = github.String("web")
-	hook.Events = []string{"issue_comment"}
+	hook.Events = []string{"issue_comment", "pull_request", "pull_request_review"}
 	hook.Config = map[string]interface{}{}
 	hook.Config["url"] = url
 	hook.Config["content_type"] = "json"
+	hook.Config["secret"] = secret
 	created, _, err := client.Repositories.CreateHook(owner, name, hook)
 	return created, err
 }
@@ -107,3 +109,24 @@ func GetUserRepos(client *github.Client) ([]github.Repository, error) {
 
 	return repos, nil
 }
+
+// splitTeam is a helper function that splits an org/team pair into
+// the organization and lowercase team name. A team without an
+// organization refers to the maintainers team of the organization.
+func splitTeam(team string) (string, string) {
+	parts := strings.SplitN(team, "/", 2)
+	if len(parts) != 2 {
+		return team, "maintainers"
+	}
+	return parts[0], strings.ToLower(parts[1])
+}
+
+// protection is a helper function that returns the branch protection
+// requiring the approval status for users other than administrators.
+func protection() *Branch {
+	in := new(Branch)
+	in.Protection.Enabled = true
+	in.Protection.Checks.Enforcement = "non_admins"
+	in.Protection.Checks.Contexts = []string{context}
+	return in
+}
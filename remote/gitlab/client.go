@@ -0,0 +1,302 @@
+package gitlab
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"golang.org/x/oauth2"
+)
+
+const (
+	pathUser           = "%suser"
+	pathUsers          = "%susers?username=%s"
+	pathGroups         = "%sgroups?per_page=100"
+	pathMembers        = "%sgroups/%s/members/all?per_page=100"
+	pathProjectMembers = "%sprojects/%s/members/all?query=%s&per_page=100"
+	pathMergedBy       = "%sprojects/%s/merge_requests?state=merged&author_username=%s&per_page=1"
+	pathProjects       = "%sprojects?min_access_level=40&per_page=100"
+	pathProject        = "%sprojects/%s"
+	pathHooks          = "%sprojects/%s/hooks"
+	pathHook           = "%sprojects/%s/hooks/%d"
+	pathProtect        = "%sprojects/%s/protected_branches?name=%s&push_access_level=40&merge_access_level=40"
+	pathNotes          = "%sprojects/%s/merge_requests/%d/notes?sort=desc&order_by=created_at&per_page=100&page=%d"
+	pathMerge          = "%sprojects/%s/merge_requests/%d"
+	pathChanges        = "%sprojects/%s/merge_requests/%d/changes"
+	pathCommit         = "%sprojects/%s/repository/commits/%s"
+	pathFile           = "%sprojects/%s/repository/files/%s?ref=%s"
+	pathStatus         = "%sprojects/%s/statuses/%s"
+	mediaTypeJSON      = "application/json"
+)
+
+type Client struct {
+	client *http.Client
+	base   string // base url
+}
+
+// NewClient returns a client at the specified url.
+func NewClient(uri string) *Client {
+	return &Client{http.DefaultClient, uri}
+}
+
+// NewClientToken returns a client at the specified url that
+// authenticates all outbound requests with the given token.
+func NewClientToken(uri, token string) *Client {
+	config := new(oauth2.Config)
+	auther := config.Client(oauth2.NoContext, &oauth2.Token{AccessToken: token})
+	return &Client{auther, uri}
+}
+
+func (c *Client) User() (*User, error) {
+	out := new(User)
+	uri := fmt.Sprintf(pathUser, c.base)
+	err := c.get(uri, out)
+	return out, err
+}
+
+func (c *Client) Users(username string) ([]*User, error) {
+	out := []*User{}
+	uri := fmt.Sprintf(pathUsers, c.base, url.QueryEscape(username))
+	err := c.get(uri, &out)
+	return out, err
+}
+
+func (c *Client) Groups() ([]*Group, error) {
+	out := []*Group{}
+	uri := fmt.Sprintf(pathGroups, c.base)
+	err := c.get(uri, &out)
+	return out, err
+}
+
+func (c *Client) Members(group string) ([]*Member, error) {
+	out := []*Member{}
+	uri := fmt.Sprintf(pathMembers, c.base, encode(group))
+	err := c.get(uri, &out)
+	return out, err
+}
+
+func (c *Client) ProjectMembers(slug, username string) ([]*Member, error) {
+	out := []*Member{}
+	uri := fmt.Sprintf(pathProjectMembers, c.base, encode(slug), url.QueryEscape(username))
+	err := c.get(uri, &out)
+	return out, err
+}
+
+func (c *Client) Projects() ([]*Project, error) {
+	out := []*Project{}
+	uri := fmt.Sprintf(pathProjects, c.base)
+	err := c.get(uri, &out)
+	return out, err
+}
+
+func (c *Client) Project(slug string) (*Project, error) {
+	out := new(Project)
+	uri := fmt.Sprintf(pathProject, c.base, encode(slug))
+	err := c.get(uri, out)
+	return out, err
+}
+
+func (c *Client) ProjectEdit(slug string, in *Project) error {
+	uri := fmt.Sprintf(pathProject, c.base, encode(slug))
+	return c.put(uri, in, nil)
+}
+
+func (c *Client) Hooks(slug string) ([]*Hook, error) {
+	out := []*Hook{}
+	uri := fmt.Sprintf(pathHooks, c.base, encode(slug))
+	err := c.get(uri, &out)
+	return out, err
+}
+
+func (c *Client) HookCreate(slug string, in *Hook) error {
+	uri := fmt.Sprintf(pathHooks, c.base, encode(slug))
+	return c.post(uri, in, nil)
+}
+
+func (c *Client) HookDelete(slug string, id int) error {
+	uri := fmt.Sprintf(pathHook, c.base, encode(slug), id)
+	return c.delete(uri)
+}
+
+func (c *Client) BranchProtect(slug, branch string) error {
+	uri := fmt.Sprintf(pathProtect, c.base, encode(slug), url.QueryEscape(branch))
+	return c.post(uri, nil, nil)
+}
+
+// Notes returns the merge request notes newest first, reading
+// the pages until the X-Next-Page header is empty.
+func (c *Client) Notes(slug string, iid int) ([]*Note, error) {
+	out := []*Note{}
+	for page := 1; page != 0; {
+		notes := []*Note{}
+		uri := fmt.Sprintf(pathNotes, c.base, encode(slug), iid, page)
+		next, err := c.page(uri, &notes)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, notes...)
+		page = next
+	}
+	return out, nil
+}
+
+func (c *Client) MergeRequest(slug string, iid int) (*MergeRequest, error) {
+	out := new(MergeRequest)
+	uri := fmt.Sprintf(pathMerge, c.base, encode(slug), iid)
+	err := c.get(uri, out)
+	return out, err
+}
+
+func (c *Client) MergedBy(slug, username string) ([]*MergeRequest, error) {
+	out := []*MergeRequest{}
+	uri := fmt.Sprintf(pathMergedBy, c.base, encode(slug), url.QueryEscape(username))
+	err := c.get(uri, &out)
+	return out, err
+}
+
+func (c *Client) Changes(slug string, iid int) ([]*Change, error) {
+	out := new(MergeRequestChanges)
+	uri := fmt.Sprintf(pathChanges, c.base, encode(slug), iid)
+	err := c.get(uri, out)
+	return out.Changes, err
+}
+
+func (c *Client) Commit(slug, sha string) (*Commit, error) {
+	out := new(Commit)
+	uri := fmt.Sprintf(pathCommit, c.base, encode(slug), sha)
+	err := c.get(uri, out)
+	return out, err
+}
+
+func (c *Client) File(slug, path, ref string) (*File, error) {
+	out := new(File)
+	uri := fmt.Sprintf(pathFile, c.base, encode(slug), encode(path), url.QueryEscape(ref))
+	err := c.get(uri, out)
+	return out, err
+}
+
+func (c *Client) StatusCreate(slug, sha string, in *Status) error {
+	uri := fmt.Sprintf(pathStatus, c.base, encode(slug), sha)
+	return c.post(uri, in, nil)
+}
+
+// encode is a helper function that url encodes a project
+// or group path, including slashes, as required by GitLab.
+func encode(path string) string {
+	return url.QueryEscape(path)
+}
+
+//
+// http request helper functions
+//
+
+// helper function for making an http GET request.
+func (c *Client) get(rawurl string, out interface{}) error {
+	return c.do(rawurl, "GET", nil, out)
+}
+
+// helper function for making an http GET request of a list, returning
+// the number of the next page from the X-Next-Page header, or zero for
+// the last page.
+func (c *Client) page(rawurl string, out interface{}) (int, error) {
+	resp, err := c.send(rawurl, "GET", nil)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	err = json.NewDecoder(resp.Body).Decode(out)
+	if err != nil {
+		return 0, err
+	}
+	next, _ := strconv.Atoi(resp.Header.Get("X-Next-Page"))
+	return next, nil
+}
+
+// helper function for making an http POST request.
+func (c *Client) post(rawurl string, in, out interface{}) error {
+	return c.do(rawurl, "POST", in, out)
+}
+
+// helper function for making an http PUT request.
+func (c *Client) put(rawurl string, in, out interface{}) error {
+	return c.do(rawurl, "PUT", in, out)
+}
+
+// helper function for making an http DELETE request.
+func (c *Client) delete(rawurl string) error {
+	return c.do(rawurl, "DELETE", nil, nil)
+}
+
+// helper function to make an http request
+func (c *Client) do(rawurl, method string, in, out interface{}) error {
+	// executes the http request and returns the body as
+	// and io.ReadCloser
+	body, err := c.stream(rawurl, method, in, out)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	// if a json response is expected, parse and return
+	// the json response.
+	if out != nil {
+		return json.NewDecoder(body).Decode(out)
+	}
+	return nil
+}
+
+// helper function to stream an http request
+func (c *Client) stream(rawurl, method string, in, out interface{}) (io.ReadCloser, error) {
+	resp, err := c.send(rawurl, method, in)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// helper function to send an http request, returning the response
+// when the request succeeds.
+func (c *Client) send(rawurl, method string, in interface{}) (*http.Response, error) {
+	uri, err := url.Parse(rawurl)
+	if err != nil {
+		return nil, err
+	}
+
+	// if we are posting or putting data, we need to
+	// write it to the body of the request.
+	var buf io.ReadWriter
+	if in != nil {
+		buf = new(bytes.Buffer)
+		err := json.NewEncoder(buf).Encode(in)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// creates a new http request to GitLab.
+	req, err := http.NewRequest(method, uri.String(), buf)
+	if err != nil {
+		return nil, err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", mediaTypeJSON)
+	}
+	req.Header.Set("Accept", mediaTypeJSON)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode > http.StatusPartialContent {
+		defer resp.Body.Close()
+		out, _ := ioutil.ReadAll(resp.Body)
+		return nil, fmt.Errorf("%s", out)
+	}
+	return resp, nil
+}
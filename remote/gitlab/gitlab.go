@@ -0,0 +1,555 @@
+package gitlab
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/lgtmco/lgtm/model"
+	"github.com/lgtmco/lgtm/shared/httputil"
+	"golang.org/x/oauth2"
+)
+
+// name of the status message posted to GitLab
+const context = "approvals/lgtm"
+
+// body of the system notes GitLab posts when a merge
+// request is approved or unapproved.
+const (
+	noteApproved   = "approved this merge request"
+	noteUnapproved = "unapproved this merge request"
+)
+
+type Gitlab struct {
+	URL    string
+	API    string
+	Client string
+	Secret string
+	Scopes []string
+}
+
+func (g *Gitlab) GetUser(res http.ResponseWriter, req *http.Request) (*model.User, error) {
+
+	var config = &oauth2.Config{
+		ClientID:     g.Client,
+		ClientSecret: g.Secret,
+		RedirectURL:  fmt.Sprintf("%s/login", httputil.GetURL(req)),
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  fmt.Sprintf("%s/oauth/authorize", g.URL),
+			TokenURL: fmt.Sprintf("%s/oauth/token", g.URL),
+		},
+		Scopes: g.Scopes,
+	}
+
+	// get the oauth code from the incoming request. if no code is present
+	// redirec the user to GitLab login to retrieve a code.
+	var code = req.FormValue("code")
+	if len(code) == 0 {
+		state := fmt.Sprintln(time.Now().Unix())
+		http.Redirect(res, req, config.AuthCodeURL(state), http.StatusSeeOther)
+		return nil, nil
+	}
+
+	// exchanges the oauth2 code for an access token
+	token, err := config.Exchange(oauth2.NoContext, code)
+	if err != nil {
+		return nil, fmt.Errorf("Error exchanging token. %s", err)
+	}
+
+	// get the currently authenticated user details for the access token
+	client := NewClientToken(g.API, token.AccessToken)
+	user, err := client.User()
+	if err != nil {
+		return nil, fmt.Errorf("Error fetching user. %s", err)
+	}
+
+	return &model.User{
+		Login:  user.Username,
+		Token:  token.AccessToken,
+		Avatar: user.Avatar,
+	}, nil
+}
+
+func (g *Gitlab) GetUserToken(token string) (string, error) {
+	client := NewClientToken(g.API, token)
+	user, err := client.User()
+	if err != nil {
+		return "", fmt.Errorf("Error fetching user. %s", err)
+	}
+	return user.Username, nil
+}
+
+func (g *Gitlab) GetTeams(user *model.User) ([]*model.Team, error) {
+	client := NewClientToken(g.API, user.Token)
+	groups, err := client.Groups()
+	if err != nil {
+		return nil, fmt.Errorf("Error fetching teams. %s", err)
+	}
+	teams := []*model.Team{}
+	for _, group := range groups {
+		teams = append(teams, &model.Team{
+			Login:  group.FullPath,
+			Avatar: group.Avatar,
+		})
+	}
+	return teams, nil
+}
+
+// GetMembers returns the members of the group with at least master
+// access, which is the GitLab equivalent of a maintainers team. An
+// org/team pair names a subgroup, so it is used as the group path.
+func (g *Gitlab) GetMembers(user *model.User, team string) ([]*model.Member, error) {
+	client := NewClientToken(g.API, user.Token)
+	members_, err := client.Members(team)
+	if err != nil {
+		return nil, fmt.Errorf("Error fetching group members. %s", err)
+	}
+	var members []*model.Member
+	for _, member := range members_ {
+		if member.AccessLevel < accessMaster {
+			continue
+		}
+		members = append(members, &model.Member{
+			Login: member.Username,
+		})
+	}
+	if len(members) == 0 {
+		return nil, fmt.Errorf("Error finding approvers for group %s", team)
+	}
+	return members, nil
+}
+
+func (g *Gitlab) GetMember(user *model.User, login string) (*model.Member, error) {
+	client := NewClientToken(g.API, user.Token)
+	users, err := client.Users(login)
+	if err != nil {
+		return nil, err
+	}
+	if len(users) == 0 {
+		return nil, fmt.Errorf("Error finding user %s", login)
+	}
+	return &model.Member{
+		Login: users[0].Username,
+	}, nil
+}
+
+func (g *Gitlab) GetRepo(user *model.User, owner, name string) (*model.Repo, error) {
+	client := NewClientToken(g.API, user.Token)
+	project, err := client.Project(owner + "/" + name)
+	if err != nil {
+		return nil, fmt.Errorf("Error fetching repository. %s", err)
+	}
+	return &model.Repo{
+		Owner:   owner,
+		Name:    name,
+		Slug:    project.PathNamespace,
+		Link:    project.WebURL,
+		Private: project.Visibility != "public",
+	}, nil
+}
+
+func (g *Gitlab) GetPerm(user *model.User, owner, name string) (*model.Perm, error) {
+	client := NewClientToken(g.API, user.Token)
+	project, err := client.Project(owner + "/" + name)
+	if err != nil {
+		return nil, fmt.Errorf("Error fetching repository. %s", err)
+	}
+
+	// the effective access level is the greater of the
+	// project and the group access level.
+	var level int
+	if access := project.Permissions.ProjectAccess; access != nil && access.AccessLevel > level {
+		level = access.AccessLevel
+	}
+	if access := project.Permissions.GroupAccess; access != nil && access.AccessLevel > level {
+		level = access.AccessLevel
+	}
+
+	m := &model.Perm{}
+	m.Admin = level >= accessMaster
+	m.Push = level >= accessDeveloper
+	m.Pull = level >= accessReporter || project.Visibility == "public"
+	return m, nil
+}
+
+func (g *Gitlab) GetRepos(u *model.User) ([]*model.Repo, error) {
+	client := NewClientToken(g.API, u.Token)
+	projects, err := client.Projects()
+	if err != nil {
+		return nil, err
+	}
+
+	repos := []*model.Repo{}
+	for _, project := range projects {
+		owner, name := splitSlug(project.PathNamespace)
+		repos = append(repos, &model.Repo{
+			Owner:   owner,
+			Name:    name,
+			Slug:    project.PathNamespace,
+			Link:    project.WebURL,
+			Private: project.Visibility != "public",
+		})
+	}
+	return repos, nil
+}
+
+func (g *Gitlab) SetHook(user *model.User, repo *model.Repo, link string) error {
+	client := NewClientToken(g.API, user.Token)
+
+	project, err := client.Project(repo.Slug)
+	if err != nil {
+		return err
+	}
+
+	old, err := getHook(client, repo.Slug, link)
+	if err == nil && old != nil {
+		client.HookDelete(repo.Slug, old.ID)
+	}
+
+	err = client.HookCreate(repo.Slug, &Hook{
+		URL:           link,
+		Token:         repo.Secret,
+		NoteEvents:    true,
+		MergeRequests: true,
+		EnableSSL:     true,
+	})
+	if err != nil {
+		log.Debugf("Error creating the webhook at %s. %s", link, err)
+		return err
+	}
+
+	// the approval status is reported as an external commit status,
+	// which blocks the merge when pipelines are required to succeed.
+	err = client.ProjectEdit(repo.Slug, &Project{MergeOnSuccess: true})
+	if err != nil {
+		log.Warnf("Error requiring successful pipelines for %s. %s", repo.Slug, err)
+	}
+	err = client.BranchProtect(repo.Slug, project.DefaultBranch)
+	if err != nil {
+		log.Warnf("Error configuring protected branch for %s@%s. %s", repo.Slug, project.DefaultBranch, err)
+	}
+	return nil
+}
+
+// SetBranches protects the branches matching the patterns. GitLab
+// supports wildcards in protected branch names, so the patterns are
+// protected as is and also apply to branches created later.
+func (g *Gitlab) SetBranches(user *model.User, repo *model.Repo, patterns []string) error {
+	client := NewClientToken(g.API, user.Token)
+
+	for _, pattern := range patterns {
+		err := client.BranchProtect(repo.Slug, pattern)
+		if err != nil {
+			log.Warnf("Error configuring protected branch for %s@%s. %s", repo.Slug, pattern, err)
+		}
+	}
+	return nil
+}
+
+func (g *Gitlab) DelHook(user *model.User, repo *model.Repo, link string) error {
+	client := NewClientToken(g.API, user.Token)
+
+	hook, err := getHook(client, repo.Slug, link)
+	if err != nil {
+		return err
+	} else if hook == nil {
+		return nil
+	}
+	return client.HookDelete(repo.Slug, hook.ID)
+}
+
+func (g *Gitlab) GetComments(u *model.User, r *model.Repo, num int) ([]*model.Comment, error) {
+	client := NewClientToken(g.API, u.Token)
+
+	notes, err := client.Notes(r.Slug, num)
+	if err != nil {
+		return nil, err
+	}
+	comments := []*model.Comment{}
+	for _, note := range notes {
+		if note.System {
+			continue
+		}
+		comments = append(comments, &model.Comment{
+			Author:  note.Author.Username,
+			Body:    note.Body,
+			Created: note.Created,
+		})
+	}
+	return comments, nil
+}
+
+// GetReviews returns the merge request approvals. GitLab records
+// approvals as system notes, which are returned newest first and
+// are reversed to match the chronological order of reviews.
+func (g *Gitlab) GetReviews(u *model.User, r *model.Repo, num int) ([]*model.Review, error) {
+	client := NewClientToken(g.API, u.Token)
+
+	notes, err := client.Notes(r.Slug, num)
+	if err != nil {
+		return nil, err
+	}
+	reviews := []*model.Review{}
+	for i := len(notes) - 1; i >= 0; i-- {
+		note := notes[i]
+		if !note.System {
+			continue
+		}
+		review := &model.Review{
+			Author:  note.Author.Username,
+			Created: note.Created,
+		}
+		switch note.Body {
+		case noteApproved:
+			review.State = model.ReviewApproved
+		case noteUnapproved:
+			review.State = model.ReviewDismissed
+		default:
+			continue
+		}
+		reviews = append(reviews, review)
+	}
+	return reviews, nil
+}
+
+func (g *Gitlab) GetContentsRef(u *model.User, r *model.Repo, path, ref string) ([]byte, error) {
+	client := NewClientToken(g.API, u.Token)
+
+	if len(ref) == 0 {
+		project, err := client.Project(r.Slug)
+		if err != nil {
+			return nil, err
+		}
+		ref = project.DefaultBranch
+	}
+	file, err := client.File(r.Slug, path, ref)
+	if err != nil {
+		return nil, err
+	}
+	if file.Encoding != "base64" {
+		return []byte(file.Content), nil
+	}
+	return base64.StdEncoding.DecodeString(file.Content)
+}
+
+func (g *Gitlab) GetHead(u *model.User, r *model.Repo, num int) (*model.Commit, error) {
+	client := NewClientToken(g.API, u.Token)
+
+	mr, err := client.MergeRequest(r.Slug, num)
+	if err != nil {
+		return nil, err
+	}
+	commit, err := client.Commit(r.Slug, mr.SHA)
+	if err != nil {
+		return nil, err
+	}
+	return &model.Commit{
+		SHA:     commit.ID,
+		Created: commit.Committed,
+	}, nil
+}
+
+func (g *Gitlab) GetIssue(u *model.User, r *model.Repo, num int) (*model.Issue, error) {
+	client := NewClientToken(g.API, u.Token)
+
+	mr, err := client.MergeRequest(r.Slug, num)
+	if err != nil {
+		return nil, err
+	}
+	return &model.Issue{
+		Number:  num,
+		Title:   mr.Title,
+		Author:  mr.Author.Username,
+		Branch:  mr.TargetBranch,
+		Base:    mr.DiffRefs.BaseSHA,
+		Draft:   mr.Draft || mr.WIP,
+		Created: mr.Created,
+	}, nil
+}
+
+// GetAuthor classifies the author as a member when the author has
+// at least developer access to the project, directly or through
+// its group, which is the GitLab equivalent of an org member.
+func (g *Gitlab) GetAuthor(u *model.User, r *model.Repo, login string) (*model.Author, error) {
+	client := NewClientToken(g.API, u.Token)
+
+	author := &model.Author{Login: login}
+	if strings.EqualFold(r.Owner, login) {
+		author.Member = true
+	} else {
+		members, err := client.ProjectMembers(r.Slug, login)
+		if err != nil {
+			return nil, fmt.Errorf("Error fetching project members. %s", err)
+		}
+		for _, member := range members {
+			if strings.EqualFold(member.Username, login) && member.AccessLevel >= accessDeveloper {
+				author.Member = true
+				break
+			}
+		}
+	}
+	merged, err := client.MergedBy(r.Slug, login)
+	if err != nil {
+		return nil, fmt.Errorf("Error fetching merge requests. %s", err)
+	}
+	author.FirstTime = len(merged) == 0
+	return author, nil
+}
+
+func (g *Gitlab) GetFiles(u *model.User, r *model.Repo, num int) ([]string, error) {
+	client := NewClientToken(g.API, u.Token)
+
+	changes, err := client.Changes(r.Slug, num)
+	if err != nil {
+		return nil, err
+	}
+	files := []string{}
+	for _, change := range changes {
+		files = append(files, change.NewPath)
+		if change.OldPath != change.NewPath {
+			files = append(files, change.OldPath)
+		}
+	}
+	return files, nil
+}
+
+func (g *Gitlab) SetStatus(u *model.User, r *model.Repo, num int, s *model.Status) error {
+	client := NewClientToken(g.API, u.Token)
+
+	mr, err := client.MergeRequest(r.Slug, num)
+	if err != nil {
+		return err
+	}
+
+	status := "success"
+	desc := "this commit looks good"
+
+	switch {
+	case s.WorkInProgress:
+		status = "pending"
+		desc = "work in progress"
+	case s.IsVetoed():
+		status = "failed"
+		desc = fmt.Sprintf("blocked by %s", strings.Join(s.Vetoes, ", "))
+	case len(s.Blockers) != 0:
+		status = "pending"
+		desc = fmt.Sprintf("changes requested by %s", strings.Join(s.Blockers, ", "))
+	case s.Granted < s.Required:
+		status = "pending"
+		desc = s.Summary()
+	case !s.WindowEnds.IsZero():
+		status = "pending"
+		desc = s.WindowSummary()
+	}
+
+	name := context
+	if len(s.Context) != 0 {
+		name = s.Context
+	}
+
+	return client.StatusCreate(r.Slug, mr.SHA, &Status{
+		State: status,
+		Name:  name,
+		Desc:  desc,
+	})
+}
+
+func (g *Gitlab) GetHook(r *http.Request) (*model.Hook, error) {
+	switch r.Header.Get("X-Gitlab-Event") {
+	case "Note Hook":
+		return g.parseNoteHook(r)
+	case "Merge Request Hook":
+		return g.parseMergeRequestHook(r)
+	default:
+		return nil, nil
+	}
+}
+
+// GetAppUser returns nil, as GitLab does not support authenticating
+// as an application.
+func (g *Gitlab) GetAppUser(r *model.Repo) (*model.User, error) {
+	return nil, nil
+}
+
+func (g *Gitlab) SetInstallation(r *http.Request) error {
+	return nil
+}
+
+func (g *Gitlab) parseNoteHook(r *http.Request) (*model.Hook, error) {
+	data := noteHook{}
+	err := json.NewDecoder(r.Body).Decode(&data)
+	if err != nil {
+		return nil, err
+	}
+
+	if data.Note.Type != "MergeRequest" {
+		return nil, nil
+	}
+
+	// the payload only includes the id of the merge request author,
+	// and the login is fetched with the merge request, authenticated
+	// as the repository owner, when the hook is evaluated.
+	hook := new(model.Hook)
+	hook.Issue = new(model.Issue)
+	hook.Issue.Number = data.MergeRequest.IID
+	hook.Issue.Title = data.MergeRequest.Title
+	hook.Issue.Branch = data.MergeRequest.TargetBranch
+	hook.Repo = new(model.Repo)
+	hook.Repo.Owner, hook.Repo.Name = splitSlug(data.Project.PathNamespace)
+	hook.Repo.Slug = data.Project.PathNamespace
+	hook.Comment = new(model.Comment)
+	hook.Comment.Body = data.Note.Body
+	hook.Comment.Author = data.User.Username
+
+	return hook, nil
+}
+
+func (g *Gitlab) parseMergeRequestHook(r *http.Request) (*model.Hook, error) {
+	data := mergeRequestHook{}
+	err := json.NewDecoder(r.Body).Decode(&data)
+	if err != nil {
+		return nil, err
+	}
+
+	// only new commits pushed to the merge request, changes to its
+	// title or draft state, and approvals, require the approval status
+	// to be re-evaluated.
+	switch {
+	case data.MergeRequest.Action == "update" && len(data.MergeRequest.OldRev) != 0:
+	case data.MergeRequest.Action == "update" && isDraftChange(data.Changes):
+	case data.MergeRequest.Action == "approved":
+	case data.MergeRequest.Action == "unapproved":
+	default:
+		return nil, nil
+	}
+
+	// the payload only includes the id of the merge request author,
+	// and the login is fetched with the merge request, authenticated
+	// as the repository owner, when the hook is evaluated.
+	hook := new(model.Hook)
+	hook.Issue = new(model.Issue)
+	hook.Issue.Number = data.MergeRequest.IID
+	hook.Issue.Title = data.MergeRequest.Title
+	hook.Issue.Branch = data.MergeRequest.TargetBranch
+	hook.Issue.Draft = data.MergeRequest.Draft || data.MergeRequest.WIP
+	hook.Repo = new(model.Repo)
+	hook.Repo.Owner, hook.Repo.Name = splitSlug(data.Project.PathNamespace)
+	hook.Repo.Slug = data.Project.PathNamespace
+
+	return hook, nil
+}
+
+// isDraftChange is a helper function that returns true if the update
+// changed the title or draft state of the merge request.
+func isDraftChange(changes map[string]json.RawMessage) bool {
+	for _, attr := range []string{"title", "draft", "work_in_progress"} {
+		if _, ok := changes[attr]; ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,318 @@
+package gitlab
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lgtmco/lgtm/model"
+
+	"github.com/franela/goblin"
+)
+
+func TestGitlab(t *testing.T) {
+
+	var server *httptest.Server
+	var requests []*http.Request
+	var payloads [][]byte
+
+	g := goblin.Goblin(t)
+	g.Describe("Gitlab", func() {
+
+		var gitlab *Gitlab
+
+		g.Before(func() {
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := ioutil.ReadAll(r.Body)
+				requests = append(requests, r)
+				payloads = append(payloads, body)
+				fakeServer(w, r)
+			}))
+		})
+		g.After(func() {
+			server.Close()
+		})
+		g.BeforeEach(func() {
+			requests = nil
+			payloads = nil
+			gitlab = &Gitlab{
+				URL: server.URL,
+				API: server.URL + "/api/v4/",
+			}
+		})
+
+		g.It("Should get the user token login", func() {
+			login, err := gitlab.GetUserToken("cfcd2084")
+			g.Assert(err == nil).IsTrue()
+			g.Assert(login).Equal("octocat")
+			g.Assert(requests[0].Header.Get("Authorization")).Equal("Bearer cfcd2084")
+		})
+
+		g.It("Should get the user groups", func() {
+			teams, err := gitlab.GetTeams(fakeUser)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(len(teams)).Equal(2)
+			g.Assert(teams[0].Login).Equal("octocat")
+			g.Assert(teams[1].Login).Equal("octocat/security")
+		})
+
+		g.It("Should get the group maintainers", func() {
+			members, err := gitlab.GetMembers(fakeUser, "octocat/security")
+			g.Assert(err == nil).IsTrue()
+			g.Assert(len(members)).Equal(2)
+			g.Assert(members[0].Login).Equal("octocat")
+			g.Assert(members[1].Login).Equal("hubot")
+		})
+
+		g.It("Should get the repository", func() {
+			repo, err := gitlab.GetRepo(fakeUser, "octocat", "hello-world")
+			g.Assert(err == nil).IsTrue()
+			g.Assert(repo.Slug).Equal("octocat/hello-world")
+			g.Assert(repo.Link).Equal("https://gitlab.com/octocat/hello-world")
+			g.Assert(repo.Private).IsTrue()
+		})
+
+		g.It("Should get the repository permissions", func() {
+			perm, err := gitlab.GetPerm(fakeUser, "octocat", "hello-world")
+			g.Assert(err == nil).IsTrue()
+			g.Assert(perm.Pull).IsTrue()
+			g.Assert(perm.Push).IsTrue()
+			g.Assert(perm.Admin).IsTrue()
+		})
+
+		g.It("Should get the repository list", func() {
+			repos, err := gitlab.GetRepos(fakeUser)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(len(repos)).Equal(1)
+			g.Assert(repos[0].Owner).Equal("octocat/security")
+			g.Assert(repos[0].Name).Equal("hello-world")
+		})
+
+		g.It("Should create the hook", func() {
+			err := gitlab.SetHook(fakeUser, fakeRepo, "http://lgtm.example.com/hook?access_token=x")
+			g.Assert(err == nil).IsTrue()
+
+			var deleted, created, protected bool
+			for i, r := range requests {
+				switch {
+				case r.Method == "DELETE" && r.URL.EscapedPath() == "/api/v4/projects/octocat%2Fhello-world/hooks/1":
+					deleted = true
+				case r.Method == "POST" && r.URL.EscapedPath() == "/api/v4/projects/octocat%2Fhello-world/hooks":
+					hook := new(Hook)
+					json.Unmarshal(payloads[i], hook)
+					g.Assert(hook.Token).Equal(fakeRepo.Secret)
+					g.Assert(hook.NoteEvents).IsTrue()
+					g.Assert(hook.MergeRequests).IsTrue()
+					created = true
+				case r.Method == "POST" && r.URL.EscapedPath() == "/api/v4/projects/octocat%2Fhello-world/protected_branches":
+					g.Assert(r.URL.Query().Get("name")).Equal("master")
+					protected = true
+				}
+			}
+			g.Assert(deleted).IsTrue()
+			g.Assert(created).IsTrue()
+			g.Assert(protected).IsTrue()
+		})
+
+		g.It("Should delete the hook", func() {
+			err := gitlab.DelHook(fakeUser, fakeRepo, "http://lgtm.example.com/hook")
+			g.Assert(err == nil).IsTrue()
+			last := requests[len(requests)-1]
+			g.Assert(last.Method).Equal("DELETE")
+			g.Assert(last.URL.EscapedPath()).Equal("/api/v4/projects/octocat%2Fhello-world/hooks/1")
+		})
+
+		g.It("Should classify the merge request author", func() {
+			author, err := gitlab.GetAuthor(fakeUser, fakeRepo, "hubot")
+			g.Assert(err == nil).IsTrue()
+			g.Assert(author.Member).IsTrue()
+			g.Assert(author.FirstTime).IsFalse()
+
+			author, err = gitlab.GetAuthor(fakeUser, fakeRepo, "spaceghost")
+			g.Assert(err == nil).IsTrue()
+			g.Assert(author.Member).IsFalse()
+			g.Assert(author.FirstTime).IsTrue()
+		})
+
+		g.It("Should get the merge request comments of every page", func() {
+			comments, err := gitlab.GetComments(fakeUser, fakeRepo, 1)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(len(comments)).Equal(2)
+			g.Assert(comments[0].Author).Equal("hubot")
+			g.Assert(comments[0].Body).Equal("LGTM")
+			g.Assert(comments[1].Author).Equal("octocat")
+		})
+
+		g.It("Should get the merge request approvals", func() {
+			reviews, err := gitlab.GetReviews(fakeUser, fakeRepo, 1)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(len(reviews)).Equal(2)
+			g.Assert(reviews[0].Author).Equal("octocat")
+			g.Assert(reviews[0].State).Equal(model.ReviewApproved)
+			g.Assert(reviews[1].State).Equal(model.ReviewDismissed)
+		})
+
+		g.It("Should get the file contents", func() {
+			data, err := gitlab.GetContentsRef(fakeUser, fakeRepo, ".lgtm", "")
+			g.Assert(err == nil).IsTrue()
+			g.Assert(string(data)).Equal("approvals = 1\n")
+			g.Assert(requests[1].URL.Query().Get("ref")).Equal("master")
+		})
+
+		g.It("Should get the head commit", func() {
+			commit, err := gitlab.GetHead(fakeUser, fakeRepo, 1)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(commit.SHA).Equal("6104942438c14ec7bd21c6cd5bd995272b3faff6")
+			g.Assert(commit.Created.IsZero()).IsFalse()
+		})
+
+		g.It("Should set the commit status", func() {
+			err := gitlab.SetStatus(fakeUser, fakeRepo, 1, &model.Status{Granted: 1, Required: 2})
+			g.Assert(err == nil).IsTrue()
+			last := requests[len(requests)-1]
+			g.Assert(last.URL.EscapedPath()).Equal("/api/v4/projects/octocat%2Fhello-world/statuses/6104942438c14ec7bd21c6cd5bd995272b3faff6")
+
+			status := new(Status)
+			json.Unmarshal(payloads[len(payloads)-1], status)
+			g.Assert(status.State).Equal("pending")
+			g.Assert(status.Name).Equal("approvals/lgtm")
+			g.Assert(status.Desc).Equal("1 of 2 required approvals granted")
+		})
+
+		g.It("Should parse a note hook", func() {
+			req, _ := http.NewRequest("POST", "/hook", bytes.NewBufferString(fakeNoteHook))
+			req.Header.Set("X-Gitlab-Event", "Note Hook")
+			hook, err := gitlab.GetHook(req)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(hook.Repo.Slug).Equal("octocat/hello-world")
+			g.Assert(hook.Repo.Owner).Equal("octocat")
+			g.Assert(hook.Repo.Name).Equal("hello-world")
+			g.Assert(hook.Issue.Number).Equal(1)
+			g.Assert(hook.Issue.Author).Equal("")
+			g.Assert(hook.Comment.Author).Equal("hubot")
+			g.Assert(hook.Comment.Body).Equal("LGTM")
+		})
+
+		g.It("Should parse a merge request push hook", func() {
+			req, _ := http.NewRequest("POST", "/hook", bytes.NewBufferString(fakeMergeHook))
+			req.Header.Set("X-Gitlab-Event", "Merge Request Hook")
+			hook, err := gitlab.GetHook(req)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(hook.Issue.Number).Equal(1)
+			g.Assert(hook.Issue.Author).Equal("")
+			g.Assert(len(requests)).Equal(0)
+		})
+
+		g.It("Should ignore other hooks", func() {
+			req, _ := http.NewRequest("POST", "/hook", bytes.NewBufferString("{}"))
+			req.Header.Set("X-Gitlab-Event", "Push Hook")
+			hook, err := gitlab.GetHook(req)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(hook == nil).IsTrue()
+		})
+	})
+}
+
+// fakeServer is a stand-in for the subset of the GitLab v4 api
+// used by the remote driver.
+func fakeServer(w http.ResponseWriter, r *http.Request) {
+	switch r.Method + " " + r.URL.EscapedPath() {
+	case "GET /api/v4/user":
+		w.Write([]byte(`{"id": 1, "username": "octocat", "avatar_url": "https://gitlab.com/octocat.png"}`))
+	case "GET /api/v4/groups":
+		w.Write([]byte(`[{"id": 1, "path": "octocat", "full_path": "octocat"}, {"id": 2, "path": "security", "full_path": "octocat/security"}]`))
+	case "GET /api/v4/groups/octocat%2Fsecurity/members/all":
+		w.Write([]byte(`[
+			{"id": 1, "username": "octocat", "access_level": 50},
+			{"id": 2, "username": "hubot", "access_level": 40},
+			{"id": 3, "username": "spaceghost", "access_level": 30}
+		]`))
+	case "GET /api/v4/projects":
+		w.Write([]byte(`[{"id": 1, "path": "hello-world", "path_with_namespace": "octocat/security/hello-world", "web_url": "https://gitlab.com/octocat/security/hello-world", "visibility": "private"}]`))
+	case "GET /api/v4/projects/octocat%2Fhello-world":
+		w.Write([]byte(`{
+			"id": 1,
+			"path": "hello-world",
+			"path_with_namespace": "octocat/hello-world",
+			"web_url": "https://gitlab.com/octocat/hello-world",
+			"visibility": "private",
+			"default_branch": "master",
+			"permissions": {"project_access": {"access_level": 30}, "group_access": {"access_level": 40}}
+		}`))
+	case "PUT /api/v4/projects/octocat%2Fhello-world":
+		w.Write([]byte(`{}`))
+	case "GET /api/v4/projects/octocat%2Fhello-world/hooks":
+		w.Write([]byte(`[{"id": 1, "url": "http://lgtm.example.com/hook?access_token=y"}]`))
+	case "POST /api/v4/projects/octocat%2Fhello-world/hooks":
+		w.WriteHeader(201)
+		w.Write([]byte(`{}`))
+	case "DELETE /api/v4/projects/octocat%2Fhello-world/hooks/1":
+		w.WriteHeader(204)
+	case "POST /api/v4/projects/octocat%2Fhello-world/protected_branches":
+		w.WriteHeader(201)
+		w.Write([]byte(`{}`))
+	case "GET /api/v4/projects/octocat%2Fhello-world/members/all":
+		switch r.FormValue("query") {
+		case "hubot":
+			w.Write([]byte(`[{"id": 2, "username": "hubot", "access_level": 40}]`))
+		case "spaceghost":
+			w.Write([]byte(`[{"id": 3, "username": "spaceghost", "access_level": 10}]`))
+		default:
+			w.Write([]byte(`[]`))
+		}
+	case "GET /api/v4/projects/octocat%2Fhello-world/merge_requests":
+		if r.FormValue("author_username") == "hubot" {
+			w.Write([]byte(`[{"iid": 2, "title": "Fix typo", "author": {"username": "hubot"}}]`))
+			return
+		}
+		w.Write([]byte(`[]`))
+	case "GET /api/v4/projects/octocat%2Fhello-world/merge_requests/1/notes":
+		if r.FormValue("page") == "2" {
+			w.Write([]byte(`[
+			{"body": "please review", "system": false, "created_at": "2016-05-01T10:00:00Z", "author": {"username": "octocat"}}
+		]`))
+			return
+		}
+		w.Header().Set("X-Next-Page", "2")
+		w.Write([]byte(`[
+			{"body": "unapproved this merge request", "system": true, "created_at": "2016-05-01T14:00:00Z", "author": {"username": "octocat"}},
+			{"body": "LGTM", "system": false, "created_at": "2016-05-01T13:00:00Z", "author": {"username": "hubot"}},
+			{"body": "approved this merge request", "system": true, "created_at": "2016-05-01T12:00:00Z", "author": {"username": "octocat"}},
+			{"body": "added 1 commit", "system": true, "created_at": "2016-05-01T11:00:00Z", "author": {"username": "octocat"}}
+		]`))
+	case "GET /api/v4/projects/octocat%2Fhello-world/merge_requests/1":
+		w.Write([]byte(`{"iid": 1, "title": "Update README", "sha": "6104942438c14ec7bd21c6cd5bd995272b3faff6", "author": {"username": "octocat"}}`))
+	case "GET /api/v4/projects/octocat%2Fhello-world/repository/commits/6104942438c14ec7bd21c6cd5bd995272b3faff6":
+		w.Write([]byte(`{"id": "6104942438c14ec7bd21c6cd5bd995272b3faff6", "committed_date": "2016-05-01T10:00:00Z"}`))
+	case "GET /api/v4/projects/octocat%2Fhello-world/repository/files/.lgtm":
+		w.Write([]byte(`{"content": "YXBwcm92YWxzID0gMQo=", "encoding": "base64"}`))
+	case "POST /api/v4/projects/octocat%2Fhello-world/statuses/6104942438c14ec7bd21c6cd5bd995272b3faff6":
+		w.WriteHeader(201)
+		w.Write([]byte(`{}`))
+	default:
+		w.WriteHeader(404)
+		w.Write([]byte(`{"message": "404 Not Found"}`))
+	}
+}
+
+var (
+	fakeUser = &model.User{Login: "octocat", Token: "cfcd2084"}
+	fakeRepo = &model.Repo{Owner: "octocat", Name: "hello-world", Slug: "octocat/hello-world", Secret: "b9015b08"}
+)
+
+var fakeNoteHook = `{
+	"object_kind": "note",
+	"user": {"username": "hubot"},
+	"project": {"path_with_namespace": "octocat/hello-world"},
+	"object_attributes": {"note": "LGTM", "noteable_type": "MergeRequest"},
+	"merge_request": {"iid": 1, "title": "Update README", "author_id": 1}
+}`
+
+var fakeMergeHook = `{
+	"object_kind": "merge_request",
+	"project": {"path_with_namespace": "octocat/hello-world"},
+	"object_attributes": {"iid": 1, "title": "Update README", "author_id": 1, "action": "update", "oldrev": "da1560886d4f094c3e6c9ef40349f7d38b5d27d7"}
+}`
@@ -0,0 +1,158 @@
+package gitlab
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// access levels defined by the GitLab permission model.
+const (
+	accessReporter  = 20
+	accessDeveloper = 30
+	accessMaster    = 40
+)
+
+type User struct {
+	ID       int    `json:"id"`
+	Username string `json:"username"`
+	Avatar   string `json:"avatar_url"`
+}
+
+type Group struct {
+	ID       int    `json:"id"`
+	Path     string `json:"path"`
+	FullPath string `json:"full_path"`
+	Avatar   string `json:"avatar_url"`
+}
+
+type Member struct {
+	ID          int    `json:"id"`
+	Username    string `json:"username"`
+	AccessLevel int    `json:"access_level"`
+}
+
+type Access struct {
+	AccessLevel int `json:"access_level"`
+}
+
+type Project struct {
+	ID            int    `json:"id"`
+	Path          string `json:"path"`
+	PathNamespace string `json:"path_with_namespace"`
+	WebURL        string `json:"web_url"`
+	Visibility    string `json:"visibility"`
+	DefaultBranch string `json:"default_branch"`
+	Permissions   struct {
+		ProjectAccess *Access `json:"project_access"`
+		GroupAccess   *Access `json:"group_access"`
+	} `json:"permissions"`
+	MergeOnSuccess bool `json:"only_allow_merge_if_pipeline_succeeds,omitempty"`
+}
+
+type Hook struct {
+	ID            int    `json:"id,omitempty"`
+	URL           string `json:"url"`
+	Token         string `json:"token,omitempty"`
+	PushEvents    bool   `json:"push_events"`
+	NoteEvents    bool   `json:"note_events"`
+	MergeRequests bool   `json:"merge_requests_events"`
+	EnableSSL     bool   `json:"enable_ssl_verification"`
+}
+
+type Note struct {
+	Body    string    `json:"body"`
+	System  bool      `json:"system"`
+	Created time.Time `json:"created_at"`
+	Author  struct {
+		Username string `json:"username"`
+	} `json:"author"`
+}
+
+type MergeRequest struct {
+	IID          int       `json:"iid"`
+	Title        string    `json:"title"`
+	SHA          string    `json:"sha"`
+	TargetBranch string    `json:"target_branch"`
+	Draft        bool      `json:"draft"`
+	WIP          bool      `json:"work_in_progress"`
+	Created      time.Time `json:"created_at"`
+	Author       struct {
+		Username string `json:"username"`
+	} `json:"author"`
+	DiffRefs struct {
+		BaseSHA string `json:"base_sha"`
+	} `json:"diff_refs"`
+}
+
+type MergeRequestChanges struct {
+	Changes []*Change `json:"changes"`
+}
+
+type Change struct {
+	OldPath string `json:"old_path"`
+	NewPath string `json:"new_path"`
+}
+
+type Commit struct {
+	ID        string    `json:"id"`
+	Committed time.Time `json:"committed_date"`
+}
+
+type File struct {
+	Content  string `json:"content"`
+	Encoding string `json:"encoding"`
+}
+
+type Status struct {
+	State string `json:"state"`
+	Name  string `json:"name"`
+	Desc  string `json:"description"`
+}
+
+// hookProject represents the project subset of the hook payload.
+type hookProject struct {
+	PathNamespace string `json:"path_with_namespace"`
+}
+
+// noteHook represents a subset of the Note Hook payload.
+type noteHook struct {
+	Kind    string      `json:"object_kind"`
+	Project hookProject `json:"project"`
+
+	Note struct {
+		Body string `json:"note"`
+		Type string `json:"noteable_type"`
+	} `json:"object_attributes"`
+
+	User struct {
+		Username string `json:"username"`
+	} `json:"user"`
+
+	MergeRequest struct {
+		IID          int    `json:"iid"`
+		Title        string `json:"title"`
+		AuthorID     int    `json:"author_id"`
+		TargetBranch string `json:"target_branch"`
+	} `json:"merge_request"`
+}
+
+// mergeRequestHook represents a subset of the Merge Request Hook payload.
+type mergeRequestHook struct {
+	Kind    string      `json:"object_kind"`
+	Project hookProject `json:"project"`
+
+	MergeRequest struct {
+		IID          int    `json:"iid"`
+		Title        string `json:"title"`
+		AuthorID     int    `json:"author_id"`
+		TargetBranch string `json:"target_branch"`
+		Draft        bool   `json:"draft"`
+		WIP          bool   `json:"work_in_progress"`
+		Action       string `json:"action"`
+		OldRev       string `json:"oldrev"`
+	} `json:"object_attributes"`
+
+	// Changes lists the attributes changed by an update,
+	// keyed by attribute name.
+	Changes map[string]json.RawMessage `json:"changes"`
+}
@@ -0,0 +1,40 @@
+package gitlab
+
+import (
+	"net/url"
+	"strings"
+)
+
+// getHook is a heper function that retrieves a hook by
+// hostname. To do this, it will retrieve a list of all hooks
+// and iterate through the list.
+func getHook(client *Client, slug, rawurl string) (*Hook, error) {
+	hooks, err := client.Hooks(slug)
+	if err != nil {
+		return nil, err
+	}
+	newurl, err := url.Parse(rawurl)
+	if err != nil {
+		return nil, err
+	}
+	for _, hook := range hooks {
+		oldurl, err := url.Parse(hook.URL)
+		if err != nil {
+			continue
+		}
+		if newurl.Host == oldurl.Host {
+			return hook, nil
+		}
+	}
+	return nil, nil
+}
+
+// splitSlug is a helper function that splits the project path into
+// the namespace and name. The namespace may include subgroups.
+func splitSlug(slug string) (string, string) {
+	i := strings.LastIndex(slug, "/")
+	if i == -1 {
+		return "", slug
+	}
+	return slug[:i], slug[i+1:]
+}
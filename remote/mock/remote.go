@@ -49,19 +49,88 @@ func (_m *Remote) GetComments(_a0 *model.User, _a1 *model.Repo, _a2 int) ([]*mod
 	return r0, r1
 }
 
-// GetContents provides a mock function with given fields: _a0, _a1, _a2
-func (_m *Remote) GetContents(_a0 *model.User, _a1 *model.Repo, _a2 string) ([]byte, error) {
-	ret := _m.Called(_a0, _a1, _a2)
+// GetContentsRef provides a mock function with given fields: _a0, _a1, _a2, _a3
+func (_m *Remote) GetContentsRef(_a0 *model.User, _a1 *model.Repo, _a2 string, _a3 string) ([]byte, error) {
+	ret := _m.Called(_a0, _a1, _a2, _a3)
 
 	var r0 []byte
-	if rf, ok := ret.Get(0).(func(*model.User, *model.Repo, string) []byte); ok {
-		r0 = rf(_a0, _a1, _a2)
+	if rf, ok := ret.Get(0).(func(*model.User, *model.Repo, string, string) []byte); ok {
+		r0 = rf(_a0, _a1, _a2, _a3)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]byte)
 		}
 	}
 
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*model.User, *model.Repo, string, string) error); ok {
+		r1 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetHead provides a mock function with given fields: _a0, _a1, _a2
+func (_m *Remote) GetHead(_a0 *model.User, _a1 *model.Repo, _a2 int) (*model.Commit, error) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 *model.Commit
+	if rf, ok := ret.Get(0).(func(*model.User, *model.Repo, int) *model.Commit); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Commit)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*model.User, *model.Repo, int) error); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetIssue provides a mock function with given fields: _a0, _a1, _a2
+func (_m *Remote) GetIssue(_a0 *model.User, _a1 *model.Repo, _a2 int) (*model.Issue, error) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 *model.Issue
+	if rf, ok := ret.Get(0).(func(*model.User, *model.Repo, int) *model.Issue); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Issue)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*model.User, *model.Repo, int) error); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAuthor provides a mock function with given fields: _a0, _a1, _a2
+func (_m *Remote) GetAuthor(_a0 *model.User, _a1 *model.Repo, _a2 string) (*model.Author, error) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 *model.Author
+	if rf, ok := ret.Get(0).(func(*model.User, *model.Repo, string) *model.Author); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Author)
+		}
+	}
+
 	var r1 error
 	if rf, ok := ret.Get(1).(func(*model.User, *model.Repo, string) error); ok {
 		r1 = rf(_a0, _a1, _a2)
@@ -72,6 +141,66 @@ func (_m *Remote) GetContents(_a0 *model.User, _a1 *model.Repo, _a2 string) ([]b
 	return r0, r1
 }
 
+// SetBranches provides a mock function with given fields: _a0, _a1, _a2
+func (_m *Remote) SetBranches(_a0 *model.User, _a1 *model.Repo, _a2 []string) error {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*model.User, *model.Repo, []string) error); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetFiles provides a mock function with given fields: _a0, _a1, _a2
+func (_m *Remote) GetFiles(_a0 *model.User, _a1 *model.Repo, _a2 int) ([]string, error) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 []string
+	if rf, ok := ret.Get(0).(func(*model.User, *model.Repo, int) []string); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*model.User, *model.Repo, int) error); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAppUser provides a mock function with given fields: _a0
+func (_m *Remote) GetAppUser(_a0 *model.Repo) (*model.User, error) {
+	ret := _m.Called(_a0)
+
+	var r0 *model.User
+	if rf, ok := ret.Get(0).(func(*model.Repo) *model.User); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.User)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*model.Repo) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetHook provides a mock function with given fields: r
 func (_m *Remote) GetHook(r *http.Request) (*model.Hook, error) {
 	ret := _m.Called(r)
@@ -95,6 +224,29 @@ func (_m *Remote) GetHook(r *http.Request) (*model.Hook, error) {
 	return r0, r1
 }
 
+// GetMember provides a mock function with given fields: _a0, _a1
+func (_m *Remote) GetMember(_a0 *model.User, _a1 string) (*model.Member, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *model.Member
+	if rf, ok := ret.Get(0).(func(*model.User, string) *model.Member); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Member)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*model.User, string) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetMembers provides a mock function with given fields: _a0, _a1
 func (_m *Remote) GetMembers(_a0 *model.User, _a1 string) ([]*model.Member, error) {
 	ret := _m.Called(_a0, _a1)
@@ -187,6 +339,29 @@ func (_m *Remote) GetRepos(_a0 *model.User) ([]*model.Repo, error) {
 	return r0, r1
 }
 
+// GetReviews provides a mock function with given fields: _a0, _a1, _a2
+func (_m *Remote) GetReviews(_a0 *model.User, _a1 *model.Repo, _a2 int) ([]*model.Review, error) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 []*model.Review
+	if rf, ok := ret.Get(0).(func(*model.User, *model.Repo, int) []*model.Review); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.Review)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*model.User, *model.Repo, int) error); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetTeams provides a mock function with given fields: _a0
 func (_m *Remote) GetTeams(_a0 *model.User) ([]*model.Team, error) {
 	ret := _m.Called(_a0)
@@ -268,13 +443,27 @@ func (_m *Remote) SetHook(_a0 *model.User, _a1 *model.Repo, _a2 string) error {
 	return r0
 }
 
-// SetStatus provides a mock function with given fields: _a0, _a1, _a2, _a3, _a4
-func (_m *Remote) SetStatus(_a0 *model.User, _a1 *model.Repo, _a2 int, _a3 int, _a4 int) error {
-	ret := _m.Called(_a0, _a1, _a2, _a3, _a4)
+// SetInstallation provides a mock function with given fields: r
+func (_m *Remote) SetInstallation(r *http.Request) error {
+	ret := _m.Called(r)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*http.Request) error); ok {
+		r0 = rf(r)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SetStatus provides a mock function with given fields: _a0, _a1, _a2, _a3
+func (_m *Remote) SetStatus(_a0 *model.User, _a1 *model.Repo, _a2 int, _a3 *model.Status) error {
+	ret := _m.Called(_a0, _a1, _a2, _a3)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(*model.User, *model.Repo, int, int, int) error); ok {
-		r0 = rf(_a0, _a1, _a2, _a3, _a4)
+	if rf, ok := ret.Get(0).(func(*model.User, *model.Repo, int, *model.Status) error); ok {
+		r0 = rf(_a0, _a1, _a2, _a3)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -3,7 +3,9 @@ package remote
 //go:generate mockery -name Remote -output mock -case=underscore
 
 import (
+	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/lgtmco/lgtm/model"
 	"golang.org/x/net/context"
@@ -20,9 +22,14 @@ type Remote interface {
 	// GetTeams gets a team list from the remote system.
 	GetTeams(*model.User) ([]*model.Team, error)
 
-	// GetMembers gets a team member list from the remote system.
+	// GetMembers gets a team member list from the remote system. The
+	// team is either an organization, in which case the members of its
+	// maintainers team are returned, or an org/team pair naming a team.
 	GetMembers(*model.User, string) ([]*model.Member, error)
 
+	// GetMember gets a user account by login from the remote system.
+	GetMember(*model.User, string) (*model.Member, error)
+
 	// GetRepo gets a repository from the remote system.
 	GetRepo(*model.User, string, string) (*model.Repo, error)
 
@@ -35,20 +42,52 @@ type Remote interface {
 	// SetHook adds a webhook to the remote repository.
 	SetHook(*model.User, *model.Repo, string) error
 
+	// SetBranches protects the repository branches matching the glob
+	// patterns, requiring the approval status before merging.
+	SetBranches(*model.User, *model.Repo, []string) error
+
 	// DelHook deletes a webhook from the remote repository.
 	DelHook(*model.User, *model.Repo, string) error
 
 	// GetComments gets pull request comments from the remote system.
 	GetComments(*model.User, *model.Repo, int) ([]*model.Comment, error)
 
-	// GetContents gets the file contents from the remote system.
-	GetContents(*model.User, *model.Repo, string) ([]byte, error)
+	// GetContentsRef gets the file contents at the git reference from
+	// the remote system. An empty reference is the default branch.
+	GetContentsRef(*model.User, *model.Repo, string, string) ([]byte, error)
+
+	// GetReviews gets pull request reviews from the remote system.
+	GetReviews(*model.User, *model.Repo, int) ([]*model.Review, error)
+
+	// GetHead gets the pull request head commit from the remote system.
+	GetHead(*model.User, *model.Repo, int) (*model.Commit, error)
+
+	// GetIssue gets the pull request from the remote system.
+	GetIssue(*model.User, *model.Repo, int) (*model.Issue, error)
+
+	// GetAuthor classifies the pull request author by login as a member
+	// of the repository owner, and as a first-time contributor to the
+	// repository, from the remote system.
+	GetAuthor(*model.User, *model.Repo, string) (*model.Author, error)
+
+	// GetFiles gets the list of files changed by the pull request
+	// from the remote system.
+	GetFiles(*model.User, *model.Repo, int) ([]string, error)
 
 	// SetStatus adds or updates the pull request status in the remote system.
-	SetStatus(*model.User, *model.Repo, int, int, int) error
+	SetStatus(*model.User, *model.Repo, int, *model.Status) error
 
 	// GetHook gets the hook from the http Request.
 	GetHook(r *http.Request) (*model.Hook, error)
+
+	// GetAppUser gets a user authenticated as the application installed
+	// in the repository, or nil if the remote system is not configured
+	// to authenticate as an application.
+	GetAppUser(*model.Repo) (*model.User, error)
+
+	// SetInstallation processes an application installation event
+	// from the http Request.
+	SetInstallation(r *http.Request) error
 }
 
 // GetUser authenticates a user with the remote system.
@@ -72,6 +111,11 @@ func GetMembers(c context.Context, u *model.User, team string) ([]*model.Member,
 	return FromContext(c).GetMembers(u, team)
 }
 
+// GetMember gets a user account by login from the remote system.
+func GetMember(c context.Context, u *model.User, login string) (*model.Member, error) {
+	return FromContext(c).GetMember(u, login)
+}
+
 // GetRepo gets a repository from the remote system.
 func GetRepo(c context.Context, u *model.User, owner, name string) (*model.Repo, error) {
 	return FromContext(c).GetRepo(u, owner, name)
@@ -92,9 +136,59 @@ func GetComments(c context.Context, u *model.User, r *model.Repo, num int) ([]*m
 	return FromContext(c).GetComments(u, r, num)
 }
 
-// GetContents gets the file contents from the remote system.
-func GetContents(c context.Context, u *model.User, r *model.Repo, path string) ([]byte, error) {
-	return FromContext(c).GetContents(u, r, path)
+// GetReviews gets pull request reviews from the remote system.
+func GetReviews(c context.Context, u *model.User, r *model.Repo, num int) ([]*model.Review, error) {
+	return FromContext(c).GetReviews(u, r, num)
+}
+
+// GetContentsRef gets the file contents at the git reference from
+// the remote system. An empty reference is the default branch.
+func GetContentsRef(c context.Context, u *model.User, r *model.Repo, path, ref string) ([]byte, error) {
+	return FromContext(c).GetContentsRef(u, r, path, ref)
+}
+
+// FindContents gets the contents of the first file found at the git
+// reference from the remote system, searching the paths in order, and
+// returns the path of the file.
+func FindContents(c context.Context, u *model.User, r *model.Repo, paths []string, ref string) (string, []byte, error) {
+	err := fmt.Errorf("No file found in %s", strings.Join(paths, ", "))
+	for _, path := range paths {
+		var data []byte
+		data, err = GetContentsRef(c, u, r, path, ref)
+		if err == nil {
+			return path, data, nil
+		}
+	}
+	return "", nil, err
+}
+
+// GetHead gets the pull request head commit from the remote system.
+func GetHead(c context.Context, u *model.User, r *model.Repo, num int) (*model.Commit, error) {
+	return FromContext(c).GetHead(u, r, num)
+}
+
+// GetIssue gets the pull request from the remote system.
+func GetIssue(c context.Context, u *model.User, r *model.Repo, num int) (*model.Issue, error) {
+	return FromContext(c).GetIssue(u, r, num)
+}
+
+// GetAuthor classifies the pull request author by login as a member
+// of the repository owner, and as a first-time contributor to the
+// repository, from the remote system.
+func GetAuthor(c context.Context, u *model.User, r *model.Repo, login string) (*model.Author, error) {
+	return FromContext(c).GetAuthor(u, r, login)
+}
+
+// GetFiles gets the list of files changed by the pull request
+// from the remote system.
+func GetFiles(c context.Context, u *model.User, r *model.Repo, num int) ([]string, error) {
+	return FromContext(c).GetFiles(u, r, num)
+}
+
+// SetBranches protects the repository branches matching the glob
+// patterns, requiring the approval status before merging.
+func SetBranches(c context.Context, u *model.User, r *model.Repo, patterns []string) error {
+	return FromContext(c).SetBranches(u, r, patterns)
 }
 
 // SetHook adds a webhook to the remote repository.
@@ -108,11 +202,24 @@ func DelHook(c context.Context, u *model.User, r *model.Repo, hook string) error
 }
 
 // SetStatus adds or updates the pull request status in the remote system.
-func SetStatus(c context.Context, u *model.User, r *model.Repo, num, granted, required int) error {
-	return FromContext(c).SetStatus(u, r, num, granted, required)
+func SetStatus(c context.Context, u *model.User, r *model.Repo, num int, status *model.Status) error {
+	return FromContext(c).SetStatus(u, r, num, status)
 }
 
 // GetHook gets the hook from the http Request.
 func GetHook(c context.Context, r *http.Request) (*model.Hook, error) {
 	return FromContext(c).GetHook(r)
 }
+
+// GetAppUser gets a user authenticated as the application installed
+// in the repository, or nil if the remote system is not configured
+// to authenticate as an application.
+func GetAppUser(c context.Context, r *model.Repo) (*model.User, error) {
+	return FromContext(c).GetAppUser(r)
+}
+
+// SetInstallation processes an application installation event
+// from the http Request.
+func SetInstallation(c context.Context, r *http.Request) error {
+	return FromContext(c).SetInstallation(r)
+}
@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"io/ioutil"
+
+	"github.com/lgtmco/lgtm/model"
+	"github.com/lgtmco/lgtm/policy"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/gin-gonic/gin"
+	"github.com/ianschenck/envflag"
+)
+
+var (
+	policyFile = envflag.String("LGTM_POLICY", "", "")
+)
+
+// Policy loads the minimum approval policy enforced by the server
+// from the LGTM_POLICY file.
+func Policy() gin.HandlerFunc {
+	var p *model.Policy
+	if len(*policyFile) != 0 {
+		data, err := ioutil.ReadFile(*policyFile)
+		if err != nil {
+			log.Fatalf("Error reading policy file. %s", err)
+		}
+		p, err = model.ParsePolicy(data)
+		if err != nil {
+			log.Fatalf("Error parsing policy file. %s", err)
+		}
+	}
+	return func(c *gin.Context) {
+		policy.ToContext(c, p)
+		c.Next()
+	}
+}
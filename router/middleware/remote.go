@@ -1,10 +1,18 @@
 package middleware
 
 import (
+	"io/ioutil"
 	"strings"
 
+	"github.com/lgtmco/lgtm/remote"
+	"github.com/lgtmco/lgtm/remote/bitbucketserver"
+	"github.com/lgtmco/lgtm/remote/gitea"
 	"github.com/lgtmco/lgtm/remote/github"
+	"github.com/lgtmco/lgtm/remote/gitlab"
 
+	"github.com/BurntSushi/toml"
+	log "github.com/Sirupsen/logrus"
+	"github.com/dgrijalva/jwt-go"
 	"github.com/gin-gonic/gin"
 	"github.com/ianschenck/envflag"
 )
@@ -13,29 +21,223 @@ const (
 	DefaultURL   = "https://github.com"
 	DefaultAPI   = "https://api.github.com/"
 	DefaultScope = "user:email,read:org,public_repo"
+
+	DefaultGitlabURL   = "https://gitlab.com"
+	DefaultGitlabScope = "api"
+
+	DefaultGiteaURL = "http://localhost:3000"
+
+	DefaultBitbucketScope = "REPO_ADMIN"
 )
 
 var (
+	remoteDriver = envflag.String("REMOTE_DRIVER", "github", "")
+	remoteConfig = envflag.String("REMOTE_CONFIG", "", "")
+
 	server = envflag.String("GITHUB_URL", DefaultURL, "")
 	client = envflag.String("GITHUB_CLIENT", "", "")
 	secret = envflag.String("GITHUB_SECRET", "", "")
 	scope  = envflag.String("GITHUB_SCOPE", DefaultScope, "")
+	checks = envflag.Bool("GITHUB_CHECKS", false, "")
+
+	appID     = envflag.Int64("GITHUB_APP_ID", 0, "")
+	appKey    = envflag.String("GITHUB_APP_KEY", "", "")
+	appSecret = envflag.String("GITHUB_APP_SECRET", "", "")
+
+	gitlabServer = envflag.String("GITLAB_URL", DefaultGitlabURL, "")
+	gitlabClient = envflag.String("GITLAB_CLIENT", "", "")
+	gitlabSecret = envflag.String("GITLAB_SECRET", "", "")
+	gitlabScope  = envflag.String("GITLAB_SCOPE", DefaultGitlabScope, "")
+
+	giteaServer = envflag.String("GITEA_URL", DefaultGiteaURL, "")
+	giteaClient = envflag.String("GITEA_CLIENT", "", "")
+	giteaSecret = envflag.String("GITEA_SECRET", "", "")
+
+	bitbucketServer = envflag.String("BITBUCKET_URL", "", "")
+	bitbucketClient = envflag.String("BITBUCKET_CLIENT", "", "")
+	bitbucketSecret = envflag.String("BITBUCKET_SECRET", "", "")
+	bitbucketScope  = envflag.String("BITBUCKET_SCOPE", DefaultBitbucketScope, "")
 )
 
+// remoteConf is the configuration of a remote. Additional named remotes
+// are loaded from the REMOTE_CONFIG file, for example:
+//
+//	[remote.enterprise]
+//	driver = "github"
+//	url    = "https://github.example.com"
+//	client = "..."
+//	secret = "..."
+//
+type remoteConf struct {
+	Driver    string `toml:"driver"`
+	URL       string `toml:"url"`
+	Client    string `toml:"client"`
+	Secret    string `toml:"secret"`
+	Scope     string `toml:"scope"`
+	AppID     int64  `toml:"app_id"`
+	AppKey    string `toml:"app_key"`
+	AppSecret string `toml:"app_secret"`
+	Checks    bool   `toml:"checks"`
+}
+
 func Remote() gin.HandlerFunc {
+	remotes := remote.Remotes{
+		"": setupRemote(defaultConf()),
+	}
+	if len(*remoteConfig) != 0 {
+		var file struct {
+			Remote map[string]remoteConf `toml:"remote"`
+		}
+		_, err := toml.DecodeFile(*remoteConfig, &file)
+		if err != nil {
+			log.Fatalf("Error parsing remote configuration. %s", err)
+		}
+		for name, conf := range file.Remote {
+			if strings.Contains(name, ":") {
+				log.Fatalf("Error configuring remote %s. Name must not contain a colon.", name)
+			}
+			remotes[name] = setupRemote(conf)
+		}
+	}
+	return func(c *gin.Context) {
+		remote.RemotesToContext(c, remotes)
+		c.Next()
+	}
+}
+
+// defaultConf returns the configuration of the default remote,
+// read from the environment.
+func defaultConf() remoteConf {
+	switch *remoteDriver {
+	case "gitlab":
+		return remoteConf{
+			Driver: *remoteDriver,
+			URL:    *gitlabServer,
+			Client: *gitlabClient,
+			Secret: *gitlabSecret,
+			Scope:  *gitlabScope,
+		}
+	case "gitea", "gogs":
+		return remoteConf{
+			Driver: *remoteDriver,
+			URL:    *giteaServer,
+			Client: *giteaClient,
+			Secret: *giteaSecret,
+		}
+	case "bitbucketserver":
+		return remoteConf{
+			Driver: *remoteDriver,
+			URL:    *bitbucketServer,
+			Client: *bitbucketClient,
+			Secret: *bitbucketSecret,
+			Scope:  *bitbucketScope,
+		}
+	default:
+		return remoteConf{
+			Driver:    *remoteDriver,
+			URL:       *server,
+			Client:    *client,
+			Secret:    *secret,
+			Scope:     *scope,
+			AppID:     *appID,
+			AppKey:    *appKey,
+			AppSecret: *appSecret,
+			Checks:    *checks,
+		}
+	}
+}
+
+func setupRemote(conf remoteConf) remote.Remote {
+	switch conf.Driver {
+	case "gitlab":
+		return setupGitlab(conf)
+	case "gitea", "gogs":
+		return setupGitea(conf)
+	case "bitbucketserver":
+		return setupBitbucketServer(conf)
+	default:
+		return setupGithub(conf)
+	}
+}
+
+func setupGithub(conf remoteConf) remote.Remote {
+	if len(conf.URL) == 0 {
+		conf.URL = DefaultURL
+	}
+	if len(conf.Scope) == 0 {
+		conf.Scope = DefaultScope
+	}
 	remote := &github.Github{
 		API:    DefaultAPI,
-		URL:    *server,
-		Client: *client,
-		Secret: *secret,
-		Scopes: strings.Split(*scope, ","),
+		URL:    conf.URL,
+		Client: conf.Client,
+		Secret: conf.Secret,
+		Scopes: strings.Split(conf.Scope, ","),
+		Checks: conf.Checks,
 	}
 	if remote.URL != DefaultURL {
 		remote.URL = strings.TrimSuffix(remote.URL, "/")
 		remote.API = remote.URL + "/api/v3/"
 	}
-	return func(c *gin.Context) {
-		c.Set("remote", remote)
-		c.Next()
+	if conf.Checks && conf.AppID == 0 {
+		log.Fatalf("Error configuring GitHub checks. Check runs are only created by a GitHub App, which requires the app id.")
+	}
+	if conf.AppID != 0 {
+		if len(conf.AppSecret) == 0 {
+			log.Fatalf("Error configuring GitHub App %d. The app webhook secret is required.", conf.AppID)
+		}
+		pem, err := ioutil.ReadFile(conf.AppKey)
+		if err != nil {
+			log.Fatalf("Error reading GitHub App private key. %s", err)
+		}
+		remote.AppKey, err = jwt.ParseRSAPrivateKeyFromPEM(pem)
+		if err != nil {
+			log.Fatalf("Error parsing GitHub App private key. %s", err)
+		}
+		remote.AppID = conf.AppID
+		remote.AppSecret = conf.AppSecret
+	}
+	return remote
+}
+
+func setupGitlab(conf remoteConf) remote.Remote {
+	if len(conf.URL) == 0 {
+		conf.URL = DefaultGitlabURL
+	}
+	if len(conf.Scope) == 0 {
+		conf.Scope = DefaultGitlabScope
+	}
+	remote := &gitlab.Gitlab{
+		URL:    strings.TrimSuffix(conf.URL, "/"),
+		Client: conf.Client,
+		Secret: conf.Secret,
+		Scopes: strings.Split(conf.Scope, ","),
+	}
+	remote.API = remote.URL + "/api/v4/"
+	return remote
+}
+
+func setupGitea(conf remoteConf) remote.Remote {
+	if len(conf.URL) == 0 {
+		conf.URL = DefaultGiteaURL
+	}
+	remote := &gitea.Gitea{
+		URL:    strings.TrimSuffix(conf.URL, "/"),
+		Client: conf.Client,
+		Secret: conf.Secret,
+	}
+	remote.API = remote.URL + "/api/v1/"
+	return remote
+}
+
+func setupBitbucketServer(conf remoteConf) remote.Remote {
+	if len(conf.Scope) == 0 {
+		conf.Scope = DefaultBitbucketScope
+	}
+	return &bitbucketserver.Bitbucket{
+		URL:    strings.TrimSuffix(conf.URL, "/"),
+		Client: conf.Client,
+		Secret: conf.Secret,
+		Scopes: strings.Split(conf.Scope, ","),
 	}
 }
@@ -0,0 +1,17 @@
+package middleware
+
+import (
+	"github.com/lgtmco/lgtm/scheduler"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Scheduler adds the scheduler of deferred jobs, which evaluates
+// pull requests again once their review window ends.
+func Scheduler() gin.HandlerFunc {
+	scheduler_ := scheduler.New()
+	return func(c *gin.Context) {
+		scheduler.ToContext(c, scheduler_)
+		c.Next()
+	}
+}
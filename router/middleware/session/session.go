@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"github.com/lgtmco/lgtm/model"
+	"github.com/lgtmco/lgtm/remote"
 	"github.com/lgtmco/lgtm/shared/token"
 	"github.com/lgtmco/lgtm/store"
 
@@ -40,13 +41,21 @@ func SetUser(c *gin.Context) {
 	// or an auth token.
 	t, err := token.ParseRequest(c.Request, func(t *token.Token) (string, error) {
 		var err error
-		user, err = store.GetUserLogin(c, t.Text)
+		name, login := model.SplitRemote(t.Text)
+		user, err = store.GetUserLogin(c, name, login)
 		return user.Secret, err
 	})
 
 	if err == nil {
 		c.Set("user", user)
 
+		// the remaining requests are made to the remote
+		// system the user authenticated with.
+		if !remote.Use(c, user.Remote) {
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+
 		// if this is a session token (ie not the API token)
 		// this means the user is accessing with a web browser,
 		// so we should implement CSRF protection measures.
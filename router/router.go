@@ -24,6 +24,7 @@ func Load(middleware ...gin.HandlerFunc) http.Handler {
 	e.Use(header.Options)
 	e.Use(header.Secure)
 	e.Use(middleware...)
+	e.Use(web.Reschedule())
 	e.Use(session.SetUser)
 
 	e.GET("/api/user", session.UserMust, api.GetUser)
@@ -32,10 +33,13 @@ func Load(middleware ...gin.HandlerFunc) http.Handler {
 	e.GET("/api/repos/:owner/:repo", session.UserMust, access.RepoPull, api.GetRepo)
 	e.POST("/api/repos/:owner/:repo", session.UserMust, access.RepoAdmin, api.PostRepo)
 	e.DELETE("/api/repos/:owner/:repo", session.UserMust, access.RepoAdmin, api.DeleteRepo)
+	e.GET("/api/repos/:owner/:repo/config", session.UserMust, access.RepoPull, api.GetConfig)
+	e.POST("/api/repos/:owner/:repo/validate", session.UserMust, access.RepoPull, api.PostValidate)
 	e.GET("/api/repos/:owner/:repo/maintainers", session.UserMust, access.RepoPull, api.GetMaintainer)
 	e.GET("/api/repos/:owner/:repo/maintainers/:org", session.UserMust, access.RepoPull, api.GetMaintainerOrg)
 
 	e.POST("/hook", web.Hook)
+	e.POST("/hook/app", web.AppHook)
 	e.GET("/login", web.Login)
 	e.POST("/login", web.LoginToken)
 	e.GET("/logout", web.Logout)
@@ -0,0 +1,23 @@
+package scheduler
+
+import "golang.org/x/net/context"
+
+const key = "scheduler"
+
+// Setter defines a context that enables setting values.
+type Setter interface {
+	Set(string, interface{})
+}
+
+// FromContext returns the Scheduler associated with this context,
+// or nil if no Scheduler is associated.
+func FromContext(c context.Context) Scheduler {
+	s, _ := c.Value(key).(Scheduler)
+	return s
+}
+
+// ToContext adds the Scheduler to this context if it supports
+// the Setter interface.
+func ToContext(c Setter, s Scheduler) {
+	c.Set(key, s)
+}
@@ -0,0 +1,56 @@
+package scheduler
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+// Scheduler defines a scheduler of deferred jobs, such as evaluating
+// the approval status of a pull request again once its review window
+// ends.
+type Scheduler interface {
+	// Schedule runs the job at the given time, replacing the job
+	// scheduled earlier with the same key.
+	Schedule(key string, at time.Time, job func())
+}
+
+// Schedule runs the job at the given time using the Scheduler
+// associated with this context, if any.
+func Schedule(c context.Context, key string, at time.Time, job func()) {
+	if s := FromContext(c); s != nil {
+		s.Schedule(key, at, job)
+	}
+}
+
+type scheduler struct {
+	sync.Mutex
+	timers map[string]*time.Timer
+}
+
+// New returns a new Scheduler that keeps the scheduled jobs in
+// memory. Jobs scheduled before the server restarts are lost, so
+// the caller stores the jobs it schedules again after a restart.
+func New() Scheduler {
+	return &scheduler{timers: map[string]*time.Timer{}}
+}
+
+func (s *scheduler) Schedule(key string, at time.Time, job func()) {
+	s.Lock()
+	defer s.Unlock()
+
+	if timer, ok := s.timers[key]; ok {
+		timer.Stop()
+	}
+	var timer *time.Timer
+	timer = time.AfterFunc(at.Sub(time.Now()), func() {
+		s.Lock()
+		if s.timers[key] == timer {
+			delete(s.timers, key)
+		}
+		s.Unlock()
+		job()
+	})
+	s.timers[key] = timer
+}
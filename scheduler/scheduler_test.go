@@ -0,0 +1,49 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/franela/goblin"
+	"github.com/gin-gonic/gin"
+)
+
+func TestScheduler(t *testing.T) {
+
+	g := goblin.Goblin(t)
+	g.Describe("Scheduler", func() {
+
+		g.It("Should run a scheduled job", func() {
+			done := make(chan string, 1)
+			s := New()
+			s.Schedule("octocat/hello-world#1", time.Now().Add(time.Millisecond), func() {
+				done <- "first"
+			})
+			g.Assert(<-done).Equal("first")
+		})
+
+		g.It("Should replace a job with the same key", func() {
+			done := make(chan string, 2)
+			s := New()
+			s.Schedule("octocat/hello-world#1", time.Now().Add(time.Millisecond*10), func() {
+				done <- "first"
+			})
+			s.Schedule("octocat/hello-world#1", time.Now().Add(time.Millisecond*20), func() {
+				done <- "second"
+			})
+			g.Assert(<-done).Equal("second")
+			select {
+			case job := <-done:
+				g.Fail("Unexpected job " + job)
+			case <-time.After(time.Millisecond * 20):
+			}
+		})
+
+		g.It("Should ignore a context without a scheduler", func() {
+			c := new(gin.Context)
+			Schedule(c, "octocat/hello-world#1", time.Now(), func() {
+				g.Fail("Unexpected job")
+			})
+		})
+	})
+}
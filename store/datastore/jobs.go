@@ -0,0 +1,40 @@
+package datastore
+
+import (
+	"github.com/lgtmco/lgtm/model"
+
+	"github.com/russross/meddler"
+)
+
+func (db *datastore) GetJobList() ([]*model.Job, error) {
+	var jobs = []*model.Job{}
+	var err = meddler.QueryAll(db, &jobs, jobListQuery)
+	return jobs, err
+}
+
+func (db *datastore) CreateJob(job *model.Job) error {
+	var _, err = db.Exec(jobDeleteStmt, job.RepoID, job.Number)
+	if err != nil {
+		return err
+	}
+	return meddler.Insert(db, jobTable, job)
+}
+
+func (db *datastore) DeleteJob(job *model.Job) error {
+	var _, err = db.Exec(jobDeleteStmt, job.RepoID, job.Number)
+	return err
+}
+
+const jobTable = "jobs"
+
+const jobListQuery = `
+SELECT *
+FROM jobs
+ORDER BY job_time
+`
+
+const jobDeleteStmt = `
+DELETE FROM jobs
+WHERE job_repo_id = ?
+  AND job_number = ?
+`
@@ -0,0 +1,50 @@
+package datastore
+
+import (
+	"testing"
+
+	"github.com/franela/goblin"
+	"github.com/lgtmco/lgtm/model"
+)
+
+func Test_jobstore(t *testing.T) {
+	db := openTest()
+	defer db.Close()
+
+	s := From(db)
+	g := goblin.Goblin(t)
+	g.Describe("Job", func() {
+
+		// before each test be sure to purge the package
+		// table data from the database.
+		g.BeforeEach(func() {
+			db.Exec("DELETE FROM jobs")
+		})
+
+		g.It("Should Add a Job", func() {
+			job := model.Job{RepoID: 1, Number: 42, Time: 1451606400}
+			err := s.CreateJob(&job)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(job.ID != 0).IsTrue()
+		})
+
+		g.It("Should Replace a Job of the same pull request", func() {
+			s.CreateJob(&model.Job{RepoID: 1, Number: 42, Time: 1451606400})
+			s.CreateJob(&model.Job{RepoID: 1, Number: 42, Time: 1451610000})
+			s.CreateJob(&model.Job{RepoID: 1, Number: 43, Time: 1451606400})
+			jobs, err := s.GetJobList()
+			g.Assert(err == nil).IsTrue()
+			g.Assert(len(jobs)).Equal(2)
+			g.Assert(jobs[1].Time).Equal(int64(1451610000))
+		})
+
+		g.It("Should Delete a Job", func() {
+			job := model.Job{RepoID: 1, Number: 42, Time: 1451606400}
+			s.CreateJob(&job)
+			err := s.DeleteJob(&job)
+			jobs, _ := s.GetJobList()
+			g.Assert(err == nil).IsTrue()
+			g.Assert(len(jobs)).Equal(0)
+		})
+	})
+}
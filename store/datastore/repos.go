@@ -15,17 +15,17 @@ func (db *datastore) GetRepo(id int64) (*model.Repo, error) {
 	return repo, err
 }
 
-func (db *datastore) GetRepoSlug(slug string) (*model.Repo, error) {
+func (db *datastore) GetRepoSlug(remote, slug string) (*model.Repo, error) {
 	var repo = new(model.Repo)
-	var err = meddler.QueryRow(db, repo, repoSlugQuery, slug)
+	var err = meddler.QueryRow(db, repo, repoSlugQuery, remote, slug)
 	return repo, err
 }
 
-func (db *datastore) GetRepoMulti(slug ...string) ([]*model.Repo, error) {
+func (db *datastore) GetRepoMulti(remote string, slug ...string) ([]*model.Repo, error) {
 	var repos = []*model.Repo{}
 	var instr, params = toList(slug)
 	var stmt = fmt.Sprintf(repoListQuery, instr)
-	var err = meddler.QueryAll(db, &repos, stmt, params...)
+	var err = meddler.QueryAll(db, &repos, stmt, append([]interface{}{remote}, params...)...)
 	return repos, err
 }
 
@@ -68,7 +68,8 @@ const repoTable = "repos"
 const repoSlugQuery = `
 SELECT *
 FROM repos
-WHERE repo_slug = ?
+WHERE repo_remote = ?
+  AND repo_slug = ?
 LIMIT 1;
 `
 
@@ -81,7 +82,8 @@ WHERE repo_owner = ?
 const repoListQuery = `
 SELECT *
 FROM repos
-WHERE repo_slug IN (%s)
+WHERE repo_remote = ?
+  AND repo_slug IN (%s)
 ORDER BY repo_slug
 `
 
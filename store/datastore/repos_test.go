@@ -78,7 +78,7 @@ func Test_repostore(t *testing.T) {
 				Name:   "drone",
 			}
 			s.CreateRepo(&repo)
-			getrepo, err := s.GetRepoSlug(repo.Slug)
+			getrepo, err := s.GetRepoSlug(repo.Remote, repo.Slug)
 			g.Assert(err == nil).IsTrue()
 			g.Assert(repo.ID).Equal(getrepo.ID)
 			g.Assert(repo.UserID).Equal(getrepo.UserID)
@@ -109,7 +109,7 @@ func Test_repostore(t *testing.T) {
 			s.CreateRepo(repo2)
 			s.CreateRepo(repo3)
 
-			repos, err := s.GetRepoMulti("octocat/fork-knife", "octocat/hello-world")
+			repos, err := s.GetRepoMulti("", "octocat/fork-knife", "octocat/hello-world")
 			g.Assert(err == nil).IsTrue()
 			g.Assert(len(repos)).Equal(2)
 			g.Assert(repos[0].ID).Equal(repo2.ID)
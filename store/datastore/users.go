@@ -12,9 +12,9 @@ func (db *datastore) GetUser(id int64) (*model.User, error) {
 	return usr, err
 }
 
-func (db *datastore) GetUserLogin(login string) (*model.User, error) {
+func (db *datastore) GetUserLogin(remote, login string) (*model.User, error) {
 	var usr = new(model.User)
-	var err = meddler.QueryRow(db, usr, userLoginQuery, login)
+	var err = meddler.QueryRow(db, usr, userLoginQuery, remote, login)
 	return usr, err
 }
 
@@ -36,7 +36,8 @@ const userTable = "users"
 const userLoginQuery = `
 SELECT *
 FROM users
-WHERE user_login=?
+WHERE user_remote=?
+  AND user_login=?
 LIMIT 1
 `
 
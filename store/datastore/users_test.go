@@ -74,7 +74,7 @@ func Test_userstore(t *testing.T) {
 				Token: "e42080dddf012c718e476da161d21ad5",
 			}
 			s.CreateUser(&user)
-			getuser, err := s.GetUserLogin(user.Login)
+			getuser, err := s.GetUserLogin(user.Remote, user.Login)
 			g.Assert(err == nil).IsTrue()
 			g.Assert(user.ID).Equal(getuser.ID)
 			g.Assert(user.Login).Equal(getuser.Login)
@@ -97,6 +97,27 @@ func Test_userstore(t *testing.T) {
 			g.Assert(err2 == nil).IsFalse()
 		})
 
+		g.It("Should Allow the Same Login on Another Remote", func() {
+			user1 := model.User{
+				Login: "joe",
+				Email: "foo@bar.com",
+				Token: "e42080dddf012c718e476da161d21ad5",
+			}
+			user2 := model.User{
+				Login:  "joe",
+				Email:  "foo@bar.com",
+				Token:  "ab20g0ddaf012c744e136da16aa21ad9",
+				Remote: "enterprise",
+			}
+			err1 := s.CreateUser(&user1)
+			err2 := s.CreateUser(&user2)
+			getuser, err3 := s.GetUserLogin("enterprise", "joe")
+			g.Assert(err1 == nil).IsTrue()
+			g.Assert(err2 == nil).IsTrue()
+			g.Assert(err3 == nil).IsTrue()
+			g.Assert(getuser.ID).Equal(user2.ID)
+		})
+
 		g.It("Should Del a User", func() {
 			user := model.User{
 				Login: "joe",
@@ -0,0 +1,21 @@
+-- +migrate Up
+
+ALTER TABLE users ADD COLUMN user_remote VARCHAR(255) DEFAULT '';
+ALTER TABLE users DROP INDEX user_login;
+ALTER TABLE users ADD UNIQUE (user_login, user_remote);
+
+ALTER TABLE repos ADD COLUMN repo_remote VARCHAR(255) DEFAULT '';
+ALTER TABLE repos DROP INDEX repo_slug;
+ALTER TABLE repos ADD UNIQUE (repo_slug, repo_remote);
+
+-- +migrate Down
+
+DELETE FROM repos WHERE repo_remote <> '';
+ALTER TABLE repos DROP INDEX repo_slug;
+ALTER TABLE repos ADD UNIQUE (repo_slug);
+ALTER TABLE repos DROP COLUMN repo_remote;
+
+DELETE FROM users WHERE user_remote <> '';
+ALTER TABLE users DROP INDEX user_login;
+ALTER TABLE users ADD UNIQUE (user_login);
+ALTER TABLE users DROP COLUMN user_remote;
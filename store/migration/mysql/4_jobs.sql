@@ -0,0 +1,14 @@
+-- +migrate Up
+
+CREATE TABLE IF NOT EXISTS jobs (
+ job_id       INTEGER PRIMARY KEY AUTO_INCREMENT
+,job_repo_id  INTEGER
+,job_number   INTEGER
+,job_time     BIGINT
+
+,UNIQUE(job_repo_id, job_number)
+);
+
+-- +migrate Down
+
+DROP TABLE jobs;
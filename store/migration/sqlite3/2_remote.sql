@@ -0,0 +1,93 @@
+-- +migrate Up
+
+ALTER TABLE users RENAME TO users_old;
+
+CREATE TABLE users (
+ user_id      INTEGER PRIMARY KEY AUTOINCREMENT
+,user_login   TEXT
+,user_token   TEXT
+,user_email   TEXT
+,user_avatar  TEXT
+,user_secret  TEXT
+,user_remote  TEXT DEFAULT ''
+
+,UNIQUE(user_login, user_remote)
+);
+
+INSERT INTO users (user_id, user_login, user_token, user_email, user_avatar, user_secret, user_remote)
+SELECT user_id, user_login, user_token, user_email, user_avatar, user_secret, ''
+FROM users_old;
+
+DROP TABLE users_old;
+
+ALTER TABLE repos RENAME TO repos_old;
+
+CREATE TABLE repos (
+ repo_id       INTEGER PRIMARY KEY AUTOINCREMENT
+,repo_user_id  INTEGER
+,repo_owner    TEXT
+,repo_name     TEXT
+,repo_slug     TEXT
+,repo_link     TEXT
+,repo_private  BOOLEAN
+,repo_secret   TEXT
+,repo_remote   TEXT DEFAULT ''
+
+,UNIQUE(repo_slug, repo_remote)
+);
+
+INSERT INTO repos (repo_id, repo_user_id, repo_owner, repo_name, repo_slug, repo_link, repo_private, repo_secret, repo_remote)
+SELECT repo_id, repo_user_id, repo_owner, repo_name, repo_slug, repo_link, repo_private, repo_secret, ''
+FROM repos_old;
+
+DROP TABLE repos_old;
+
+CREATE INDEX IF NOT EXISTS ix_repo_owner   ON repos (repo_owner);
+CREATE INDEX IF NOT EXISTS ix_repo_user_id ON repos (repo_user_id);
+
+-- +migrate Down
+
+ALTER TABLE repos RENAME TO repos_old;
+
+CREATE TABLE repos (
+ repo_id       INTEGER PRIMARY KEY AUTOINCREMENT
+,repo_user_id  INTEGER
+,repo_owner    TEXT
+,repo_name     TEXT
+,repo_slug     TEXT
+,repo_link     TEXT
+,repo_private  BOOLEAN
+,repo_secret   TEXT
+
+,UNIQUE(repo_slug)
+);
+
+INSERT INTO repos (repo_id, repo_user_id, repo_owner, repo_name, repo_slug, repo_link, repo_private, repo_secret)
+SELECT repo_id, repo_user_id, repo_owner, repo_name, repo_slug, repo_link, repo_private, repo_secret
+FROM repos_old
+WHERE repo_remote = '';
+
+DROP TABLE repos_old;
+
+CREATE INDEX IF NOT EXISTS ix_repo_owner   ON repos (repo_owner);
+CREATE INDEX IF NOT EXISTS ix_repo_user_id ON repos (repo_user_id);
+
+ALTER TABLE users RENAME TO users_old;
+
+CREATE TABLE users (
+ user_id      INTEGER PRIMARY KEY AUTOINCREMENT
+,user_login   TEXT
+,user_token   TEXT
+,user_email   TEXT
+,user_avatar  TEXT
+,user_secret  TEXT
+
+,UNIQUE(user_login)
+);
+
+INSERT INTO users (user_id, user_login, user_token, user_email, user_avatar, user_secret)
+SELECT user_id, user_login, user_token, user_email, user_avatar, user_secret
+FROM users_old
+WHERE user_remote = '';
+
+DROP TABLE users_old;
@@ -0,0 +1,30 @@
+-- +migrate Up
+
+ALTER TABLE repos ADD COLUMN repo_signed BOOLEAN DEFAULT 0;
+
+-- +migrate Down
+
+ALTER TABLE repos RENAME TO repos_old;
+
+CREATE TABLE repos (
+ repo_id       INTEGER PRIMARY KEY AUTOINCREMENT
+,repo_user_id  INTEGER
+,repo_owner    TEXT
+,repo_name     TEXT
+,repo_slug     TEXT
+,repo_link     TEXT
+,repo_private  BOOLEAN
+,repo_secret   TEXT
+,repo_remote   TEXT DEFAULT ''
+
+,UNIQUE(repo_slug, repo_remote)
+);
+
+INSERT INTO repos (repo_id, repo_user_id, repo_owner, repo_name, repo_slug, repo_link, repo_private, repo_secret, repo_remote)
+SELECT repo_id, repo_user_id, repo_owner, repo_name, repo_slug, repo_link, repo_private, repo_secret, repo_remote
+FROM repos_old;
+
+DROP TABLE repos_old;
+
+CREATE INDEX IF NOT EXISTS ix_repo_owner   ON repos (repo_owner);
+CREATE INDEX IF NOT EXISTS ix_repo_user_id ON repos (repo_user_id);
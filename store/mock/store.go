@@ -10,6 +10,20 @@ type Store struct {
 	mock.Mock
 }
 
+// CreateJob provides a mock function with given fields: _a0
+func (_m *Store) CreateJob(_a0 *model.Job) error {
+	ret := _m.Called(_a0)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*model.Job) error); ok {
+		r0 = rf(_a0)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // CreateRepo provides a mock function with given fields: _a0
 func (_m *Store) CreateRepo(_a0 *model.Repo) error {
 	ret := _m.Called(_a0)
@@ -38,6 +52,20 @@ func (_m *Store) CreateUser(_a0 *model.User) error {
 	return r0
 }
 
+// DeleteJob provides a mock function with given fields: _a0
+func (_m *Store) DeleteJob(_a0 *model.Job) error {
+	ret := _m.Called(_a0)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*model.Job) error); ok {
+		r0 = rf(_a0)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // DeleteRepo provides a mock function with given fields: _a0
 func (_m *Store) DeleteRepo(_a0 *model.Repo) error {
 	ret := _m.Called(_a0)
@@ -66,6 +94,29 @@ func (_m *Store) DeleteUser(_a0 *model.User) error {
 	return r0
 }
 
+// GetJobList provides a mock function with given fields:
+func (_m *Store) GetJobList() ([]*model.Job, error) {
+	ret := _m.Called()
+
+	var r0 []*model.Job
+	if rf, ok := ret.Get(0).(func() []*model.Job); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.Job)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetRepo provides a mock function with given fields: _a0
 func (_m *Store) GetRepo(_a0 int64) (*model.Repo, error) {
 	ret := _m.Called(_a0)
@@ -89,13 +140,13 @@ func (_m *Store) GetRepo(_a0 int64) (*model.Repo, error) {
 	return r0, r1
 }
 
-// GetRepoMulti provides a mock function with given fields: _a0
-func (_m *Store) GetRepoMulti(_a0 ...string) ([]*model.Repo, error) {
-	ret := _m.Called(_a0)
+// GetRepoMulti provides a mock function with given fields: _a0, _a1
+func (_m *Store) GetRepoMulti(_a0 string, _a1 ...string) ([]*model.Repo, error) {
+	ret := _m.Called(_a0, _a1)
 
 	var r0 []*model.Repo
-	if rf, ok := ret.Get(0).(func(...string) []*model.Repo); ok {
-		r0 = rf(_a0...)
+	if rf, ok := ret.Get(0).(func(string, ...string) []*model.Repo); ok {
+		r0 = rf(_a0, _a1...)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]*model.Repo)
@@ -103,8 +154,8 @@ func (_m *Store) GetRepoMulti(_a0 ...string) ([]*model.Repo, error) {
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(...string) error); ok {
-		r1 = rf(_a0...)
+	if rf, ok := ret.Get(1).(func(string, ...string) error); ok {
+		r1 = rf(_a0, _a1...)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -135,13 +186,13 @@ func (_m *Store) GetRepoOwner(_a0 string) ([]*model.Repo, error) {
 	return r0, r1
 }
 
-// GetRepoSlug provides a mock function with given fields: _a0
-func (_m *Store) GetRepoSlug(_a0 string) (*model.Repo, error) {
-	ret := _m.Called(_a0)
+// GetRepoSlug provides a mock function with given fields: _a0, _a1
+func (_m *Store) GetRepoSlug(_a0 string, _a1 string) (*model.Repo, error) {
+	ret := _m.Called(_a0, _a1)
 
 	var r0 *model.Repo
-	if rf, ok := ret.Get(0).(func(string) *model.Repo); ok {
-		r0 = rf(_a0)
+	if rf, ok := ret.Get(0).(func(string, string) *model.Repo); ok {
+		r0 = rf(_a0, _a1)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*model.Repo)
@@ -149,8 +200,8 @@ func (_m *Store) GetRepoSlug(_a0 string) (*model.Repo, error) {
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(_a0)
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(_a0, _a1)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -181,13 +232,13 @@ func (_m *Store) GetUser(_a0 int64) (*model.User, error) {
 	return r0, r1
 }
 
-// GetUserLogin provides a mock function with given fields: _a0
-func (_m *Store) GetUserLogin(_a0 string) (*model.User, error) {
-	ret := _m.Called(_a0)
+// GetUserLogin provides a mock function with given fields: _a0, _a1
+func (_m *Store) GetUserLogin(_a0 string, _a1 string) (*model.User, error) {
+	ret := _m.Called(_a0, _a1)
 
 	var r0 *model.User
-	if rf, ok := ret.Get(0).(func(string) *model.User); ok {
-		r0 = rf(_a0)
+	if rf, ok := ret.Get(0).(func(string, string) *model.User); ok {
+		r0 = rf(_a0, _a1)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*model.User)
@@ -195,8 +246,8 @@ func (_m *Store) GetUserLogin(_a0 string) (*model.User, error) {
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(_a0)
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(_a0, _a1)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -16,8 +16,8 @@ type Store interface {
 	// GetUser gets a user by unique ID.
 	GetUser(int64) (*model.User, error)
 
-	// GetUserLogin gets a user by unique Login name.
-	GetUserLogin(string) (*model.User, error)
+	// GetUserLogin gets a user by remote and unique Login name.
+	GetUserLogin(string, string) (*model.User, error)
 
 	// CreateUser creates a new user account.
 	CreateUser(*model.User) error
@@ -31,11 +31,11 @@ type Store interface {
 	// GetRepo gets a repo by unique ID.
 	GetRepo(int64) (*model.Repo, error)
 
-	// GetRepoSlug gets a repo by its full name.
-	GetRepoSlug(string) (*model.Repo, error)
+	// GetRepoSlug gets a repo by remote and its full name.
+	GetRepoSlug(string, string) (*model.Repo, error)
 
-	// GetRepoMulti gets a list of multiple repos by their full name.
-	GetRepoMulti(...string) ([]*model.Repo, error)
+	// GetRepoMulti gets a list of multiple repos by remote and their full name.
+	GetRepoMulti(string, ...string) ([]*model.Repo, error)
 
 	// GetRepoOwner gets a list by owner.
 	GetRepoOwner(string) ([]*model.Repo, error)
@@ -48,6 +48,16 @@ type Store interface {
 
 	// DeleteRepo deletes a user repository.
 	DeleteRepo(*model.Repo) error
+
+	// GetJobList gets the list of scheduled jobs.
+	GetJobList() ([]*model.Job, error)
+
+	// CreateJob creates a scheduled job, replacing the job
+	// scheduled earlier for the same pull request.
+	CreateJob(*model.Job) error
+
+	// DeleteJob deletes the scheduled job of a pull request.
+	DeleteJob(*model.Job) error
 }
 
 // GetUser gets a user by unique ID.
@@ -55,9 +65,9 @@ func GetUser(c context.Context, id int64) (*model.User, error) {
 	return FromContext(c).GetUser(id)
 }
 
-// GetUserLogin gets a user by unique Login name.
-func GetUserLogin(c context.Context, login string) (*model.User, error) {
-	return FromContext(c).GetUserLogin(login)
+// GetUserLogin gets a user by remote and unique Login name.
+func GetUserLogin(c context.Context, remote, login string) (*model.User, error) {
+	return FromContext(c).GetUserLogin(remote, login)
 }
 
 // CreateUser creates a new user account.
@@ -80,19 +90,19 @@ func GetRepo(c context.Context, id int64) (*model.Repo, error) {
 	return FromContext(c).GetRepo(id)
 }
 
-// GetRepoSlug gets a repo by its full name.
-func GetRepoSlug(c context.Context, slug string) (*model.Repo, error) {
-	return FromContext(c).GetRepoSlug(slug)
+// GetRepoSlug gets a repo by remote and its full name.
+func GetRepoSlug(c context.Context, remote, slug string) (*model.Repo, error) {
+	return FromContext(c).GetRepoSlug(remote, slug)
 }
 
-// GetRepoOwnerName gets a repo by its owner and name.
-func GetRepoOwnerName(c context.Context, owner, name string) (*model.Repo, error) {
-	return GetRepoSlug(c, path.Join(owner, name))
+// GetRepoOwnerName gets a repo by remote and its owner and name.
+func GetRepoOwnerName(c context.Context, remote, owner, name string) (*model.Repo, error) {
+	return GetRepoSlug(c, remote, path.Join(owner, name))
 }
 
-// GetRepoMulti gets a list of multiple repos by their full name.
-func GetRepoMulti(c context.Context, slug ...string) ([]*model.Repo, error) {
-	return FromContext(c).GetRepoMulti(slug...)
+// GetRepoMulti gets a list of multiple repos by remote and their full name.
+func GetRepoMulti(c context.Context, remote string, slug ...string) ([]*model.Repo, error) {
+	return FromContext(c).GetRepoMulti(remote, slug...)
 }
 
 // GetRepoOwner gets a repo list by account.
@@ -100,19 +110,19 @@ func GetRepoOwner(c context.Context, owner string) ([]*model.Repo, error) {
 	return FromContext(c).GetRepoOwner(owner)
 }
 
-// GetRepoIntersect gets a repo list by account login.
-func GetRepoIntersect(c context.Context, repos []*model.Repo) ([]*model.Repo, error) {
+// GetRepoIntersect gets a repo list by remote and account login.
+func GetRepoIntersect(c context.Context, remote string, repos []*model.Repo) ([]*model.Repo, error) {
 	slugs := make([]string, len(repos))
 	for i, repo := range repos {
 		slugs[i] = repo.Slug
 	}
-	return GetRepoMulti(c, slugs...)
+	return GetRepoMulti(c, remote, slugs...)
 }
 
 // GetRepoIntersectMap gets a repo set by account login where the key is
 // the repository slug and the value is the repository struct.
-func GetRepoIntersectMap(c context.Context, repos []*model.Repo) (map[string]*model.Repo, error) {
-	repos, err := GetRepoIntersect(c, repos)
+func GetRepoIntersectMap(c context.Context, remote string, repos []*model.Repo) (map[string]*model.Repo, error) {
+	repos, err := GetRepoIntersect(c, remote, repos)
 	if err != nil {
 		return nil, err
 	}
@@ -137,3 +147,19 @@ func UpdateRepo(c context.Context, repo *model.Repo) error {
 func DeleteRepo(c context.Context, repo *model.Repo) error {
 	return FromContext(c).DeleteRepo(repo)
 }
+
+// GetJobList gets the list of scheduled jobs.
+func GetJobList(c context.Context) ([]*model.Job, error) {
+	return FromContext(c).GetJobList()
+}
+
+// CreateJob creates a scheduled job, replacing the job
+// scheduled earlier for the same pull request.
+func CreateJob(c context.Context, job *model.Job) error {
+	return FromContext(c).CreateJob(job)
+}
+
+// DeleteJob deletes the scheduled job of a pull request.
+func DeleteJob(c context.Context, job *model.Job) error {
+	return FromContext(c).DeleteJob(job)
+}
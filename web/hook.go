@@ -1,11 +1,28 @@
 package web
 
 import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io/ioutil"
+	"net/http"
 	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/lgtmco/lgtm/cache"
 	"github.com/lgtmco/lgtm/model"
+	"github.com/lgtmco/lgtm/policy"
 	"github.com/lgtmco/lgtm/remote"
+	"github.com/lgtmco/lgtm/scheduler"
+	"github.com/lgtmco/lgtm/shared/httputil"
+	"github.com/lgtmco/lgtm/shared/token"
 	"github.com/lgtmco/lgtm/store"
 
 	log "github.com/Sirupsen/logrus"
@@ -13,6 +30,53 @@ import (
 )
 
 func Hook(c *gin.Context) {
+	// the payload is buffered so that the signature can be verified
+	// before the remote parses the request body.
+	payload, err := ioutil.ReadAll(c.Request.Body)
+	if err != nil {
+		log.Errorf("Error reading hook payload. %s", err)
+		c.String(500, "Error reading hook payload. %s", err)
+		return
+	}
+	c.Request.Body = ioutil.NopCloser(bytes.NewBuffer(payload))
+
+	// the hook url includes a signed token that identifies the
+	// repository and is verified using the repository secret.
+	var repo *model.Repo
+	_, err = token.Parse(c.Query("access_token"), func(t *token.Token) (string, error) {
+		if t.Kind != token.HookToken {
+			return "", fmt.Errorf("Invalid token kind %s", t.Kind)
+		}
+		var err error
+		name, slug := model.SplitRemote(t.Text)
+		repo, err = store.GetRepoSlug(c, name, slug)
+		if err != nil {
+			return "", err
+		}
+		return repo.Secret, nil
+	})
+	if err != nil {
+		log.Errorf("Error authenticating hook. %s", err)
+		c.String(401, "Invalid or missing hook token.")
+		return
+	}
+	if !remote.Use(c, repo.Remote) {
+		log.Errorf("Error processing hook for %s. Remote %s is not configured.", repo.Slug, repo.Remote)
+		c.String(500, "Remote %s is not configured.", repo.Remote)
+		return
+	}
+	// repositories activated before hook payloads were signed have
+	// an unsigned webhook, and must be activated again to sign it.
+	if !checkSignature(c.Request, payload, repo.Secret) {
+		if !repo.Signed {
+			log.Errorf("Error authenticating hook for %s. The webhook is unsigned, activate the repository again to sign it.", repo.Slug)
+		} else {
+			log.Errorf("Error authenticating hook for %s. Invalid signature.", repo.Slug)
+		}
+		c.String(401, "Invalid or missing hook signature.")
+		return
+	}
+
 	hook, err := remote.GetHook(c, c.Request)
 	if err != nil {
 		log.Errorf("Error parsing hook. %s", err)
@@ -23,110 +87,799 @@ func Hook(c *gin.Context) {
 		c.String(200, "pong")
 		return
 	}
+	if hook.Repo.Slug != repo.Slug {
+		log.Errorf("Error authenticating hook. Token for %s used by %s.", repo.Slug, hook.Repo.Slug)
+		c.String(401, "Hook token does not match repository.")
+		return
+	}
 
-	repo, err := store.GetRepoSlug(c, hook.Repo.Slug)
-	if err != nil {
-		log.Errorf("Error getting repository %s. %s", hook.Repo.Slug, err)
-		c.String(404, "Repository not found.")
+	result, herr := evaluate(c, repo, hook.Issue)
+	if herr != nil {
+		c.String(herr.code, "%s", herr.msg)
 		return
 	}
-	user, err := store.GetUser(c, repo.UserID)
+	c.IndentedJSON(200, result)
+}
+
+// hookError is an error evaluating the approval status of a pull
+// request, with the http status code of the hook response.
+type hookError struct {
+	code int
+	msg  string
+}
+
+// evaluate is a helper function that evaluates the approval status of
+// the pull request, and updates the status in the remote system.
+func evaluate(c *gin.Context, repo *model.Repo, issue *model.Issue) (gin.H, *hookError) {
+	// when the remote is configured to authenticate as an application,
+	// the hook is processed as the application installed in the repository
+	// instead of the user that activated the repository.
+	user, err := remote.GetAppUser(c, repo)
 	if err != nil {
-		log.Errorf("Error getting repository owner %s. %s", repo.Slug, err)
-		c.String(404, "Repository owner not found.")
-		return
+		log.Errorf("Error authenticating as application for %s. %s", repo.Slug, err)
+		return nil, &hookError{500, fmt.Sprintf("Error authenticating as application. %s", err)}
+	}
+	if user == nil {
+		user, err = store.GetUser(c, repo.UserID)
+		if err != nil {
+			log.Errorf("Error getting repository owner %s. %s", repo.Slug, err)
+			return nil, &hookError{404, "Repository owner not found."}
+		}
+	}
+	user.Remote = repo.Remote
+
+	// the policy files are read at the base commit of the pull request,
+	// so that a pull request cannot change the rules used to approve it.
+	// The base commit, target branch, title, author, draft state and
+	// creation time are fetched when not included in the hook payload.
+	if len(issue.Base) == 0 {
+		if herr := getIssue(c, user, repo, issue); herr != nil {
+			return nil, herr
+		}
 	}
 
-	rcfile, _ := remote.GetContents(c, user, repo, ".lgtm")
-	config, err := model.ParseConfig(rcfile)
+	config, err := cache.GetConfig(c, user, repo, issue.Base)
 	if err != nil {
 		log.Errorf("Error parsing .lgtm file for %s. %s", repo.Slug, err)
-		c.String(500, "Error parsing .lgtm file. %s.", err)
-		return
+		return nil, &hookError{500, fmt.Sprintf("Error parsing .lgtm file. %s.", err)}
 	}
 
-	// THIS IS COMPLETELY DUPLICATED IN THE API SECTION. NOT IDEAL
-	file, err := remote.GetContents(c, user, repo, "MAINTAINERS")
-	if err != nil {
-		log.Debugf("no MAINTAINERS file for %s. Checking for team members.", repo.Slug)
-		members, merr := cache.GetMembers(c, user, repo.Owner)
-		if merr != nil {
-			log.Errorf("Error getting repository %s. %s", repo.Slug, err)
-			log.Errorf("Error getting org members %s. %s", repo.Owner, merr)
-			c.String(404, "MAINTAINERS file not found. %s", err)
-			return
-		} else {
-			for _, member := range members {
-				file = append(file, member.Login...)
-				file = append(file, '\n')
+	// the approval policy depends on the target branch of the pull request.
+	if len(config.Branches) != 0 {
+		setBranches(c, user, repo, config)
+		config = config.ForBranch(issue.Branch)
+	}
+
+	// the approval policy depends on the author of the pull request,
+	// when external, first-time or bot authors have a policy.
+	var author *model.Author
+	if len(config.Authors) != 0 {
+		if len(issue.Author) == 0 {
+			if herr := getIssue(c, user, repo, issue); herr != nil {
+				return nil, herr
 			}
 		}
+		var herr *hookError
+		author, herr = getAuthor(c, user, repo, config, issue.Author)
+		if herr != nil {
+			return nil, herr
+		}
+		config = config.ForAuthor(author)
 	}
 
-	maintainer, err := model.ParseMaintainer(file)
-	if err != nil {
-		log.Errorf("Error parsing MAINTAINERS file for %s. %s", repo.Slug, err)
-		c.String(500, "Error parsing MAINTAINERS file. %s.", err)
-		return
+	// the review window starts when the pull request is opened.
+	if config.MinOpen() != 0 && issue.Created.IsZero() {
+		if herr := getIssue(c, user, repo, issue); herr != nil {
+			return nil, herr
+		}
+	}
+
+	// path-based rules, guarded policy files and code owners are matched
+	// against the files changed by the pull request, which are only
+	// fetched when needed.
+	var files []string
+	if len(config.Rules) != 0 || config.Guard != nil || config.Source == model.SourceCodeOwners {
+		files, err = remote.GetFiles(c, user, repo, issue.Number)
+		if err != nil {
+			log.Errorf("Error retrieving files for %s pr %d. %s", repo.Slug, issue.Number, err)
+			return nil, &hookError{500, fmt.Sprintf("Error retrieving files. %s.", err)}
+		}
 	}
 
-	comments, err := remote.GetComments(c, user, repo, hook.Issue.Number)
+	var maintainer *model.Maintainer
+	if config.Source == model.SourceCodeOwners {
+		maintainer, err = getCodeOwners(c, user, repo, issue.Base, config, files)
+		if err != nil {
+			log.Errorf("Error getting CODEOWNERS file for %s. %s", repo.Slug, err)
+			return nil, &hookError{500, fmt.Sprintf("Error getting CODEOWNERS file. %s.", err)}
+		}
+	} else {
+		// THIS IS COMPLETELY DUPLICATED IN THE API SECTION. NOT IDEAL
+		path, file, err := remote.FindContents(c, user, repo, model.MaintainerPaths(), issue.Base)
+		if err != nil {
+			log.Debugf("no MAINTAINERS file for %s. Checking for team members.", repo.Slug)
+			members, merr := cache.GetMembers(c, user, repo.Owner)
+			if merr != nil {
+				log.Errorf("Error getting repository %s. %s", repo.Slug, err)
+				log.Errorf("Error getting org members %s. %s", repo.Owner, merr)
+				return nil, &hookError{404, fmt.Sprintf("MAINTAINERS file not found. %s", err)}
+			} else {
+				for _, member := range members {
+					file = append(file, member.Login...)
+					file = append(file, '\n')
+				}
+			}
+		}
+
+		maintainer, err = model.ParseMaintainerFile(path, file)
+		if err != nil {
+			log.Errorf("Error parsing MAINTAINERS file for %s. %s", repo.Slug, err)
+			return nil, &hookError{500, fmt.Sprintf("Error parsing MAINTAINERS file. %s.", err)}
+		}
+	}
+
+	comments, err := remote.GetComments(c, user, repo, issue.Number)
 	if err != nil {
-		log.Errorf("Error retrieving comments for %s pr %d. %s", repo.Slug, hook.Issue.Number, err)
-		c.String(500, "Error retrieving comments. %s.", err)
-		return
+		log.Errorf("Error retrieving comments for %s pr %d. %s", repo.Slug, issue.Number, err)
+		return nil, &hookError{500, fmt.Sprintf("Error retrieving comments. %s.", err)}
 	}
-	approvers := getApprovers(config, maintainer, hook.Issue, comments)
-	approved := len(approvers) >= config.Approvals
-	err = remote.SetStatus(c, user, repo, hook.Issue.Number, len(approvers), config.Approvals)
+	reviews, err := remote.GetReviews(c, user, repo, issue.Number)
 	if err != nil {
-		log.Errorf("Error setting status for %s pr %d. %s", repo.Slug, hook.Issue.Number, err)
-		c.String(500, "Error setting status. %s.", err)
-		return
+		log.Errorf("Error retrieving reviews for %s pr %d. %s", repo.Slug, issue.Number, err)
+		return nil, &hookError{500, fmt.Sprintf("Error retrieving reviews. %s.", err)}
+	}
+	// vetoes are not reset by a push, and are only lifted by
+	// the maintainer that posted the veto.
+	allComments, allReviews := comments, reviews
+	if config.ResetOnPush {
+		head, err := remote.GetHead(c, user, repo, issue.Number)
+		if err != nil {
+			log.Errorf("Error retrieving head commit for %s pr %d. %s", repo.Slug, issue.Number, err)
+			return nil, &hookError{500, fmt.Sprintf("Error retrieving head commit. %s.", err)}
+		}
+		comments = getCommentsSince(comments, head.Created)
+		reviews = getReviewsSince(reviews, head.Created)
+	}
+
+	link := fmt.Sprintf("%s/%s", httputil.GetURL(c.Request), repo.Owner)
+	approvers := []*model.Person{}
+	blockers := []*model.Person{}
+	results := []*model.Status{}
+	// a veto from any maintainer of the repository blocks every rule,
+	// not only the rules the maintainer is on the team of.
+	vetoes := getVetoes(config, maintainer, allComments, allReviews)
+	rules := config.Match(files)
+	for _, rule := range rules {
+		team := maintainer
+		if len(rule.Team) != 0 {
+			team, err = model.FromOrg(maintainer, rule.Team)
+			if err != nil {
+				log.Errorf("Error getting team %s for rule %s in %s. %s", rule.Team, rule.Name, repo.Slug, err)
+				return nil, &hookError{500, fmt.Sprintf("Error getting team %s for rule %s. %s.", rule.Team, rule.Name, err)}
+			}
+		}
+		// the author can never approve a change to the policy files.
+		ruleConfig := config
+		if rule.IsGuard() {
+			guarded := *config
+			guarded.SelfApprovalOff = true
+			ruleConfig = &guarded
+		}
+		ruleApprovers, ruleBlockers := getApprovers(ruleConfig, team, issue, comments, reviews)
+		ruleApprovers = removePeople(ruleApprovers, vetoes)
+		result := &model.Status{
+			Granted:  config.Granted(ruleApprovers),
+			Required: rule.Approvals,
+			Rule:     getRule(config, rule),
+			Link:     link,
+			Context:  rule.Context,
+			Pending:  getPending(ruleConfig, team, issue, ruleApprovers, appendPeople(ruleBlockers, vetoes)),
+		}
+		for _, approver := range ruleApprovers {
+			result.Approvers = append(result.Approvers, approver.Login)
+		}
+		for _, blocker := range ruleBlockers {
+			result.Blockers = append(result.Blockers, blocker.Login)
+		}
+		for _, veto := range vetoes {
+			result.Vetoes = append(result.Vetoes, veto.Login)
+		}
+		results = append(results, result)
+		approvers = appendPeople(approvers, ruleApprovers)
+		blockers = appendPeople(blockers, ruleBlockers)
+	}
+
+	// a work in progress stays pending however many approvals
+	// it is granted, until it is marked ready for review.
+	wip := config.IsWorkInProgress(issue)
+	for _, result := range results {
+		result.WorkInProgress = wip
+	}
+	status := mergeStatus(results)
+	status.WorkInProgress = wip
+
+	// an approved pull request stays pending until the review window
+	// ends, when the status is evaluated again without waiting for
+	// another comment or review.
+	if end := config.ReviewWindow(issue.Created); time.Now().Before(end) {
+		if status.IsApproved() {
+			scheduleReview(c, repo, issue.Number, end)
+		}
+		for _, result := range results {
+			result.WindowEnds = end
+		}
+		status.WindowEnds = end
+	}
+	for i, rule := range rules {
+		if rule.IsQuota() {
+			status.Quotas = append(status.Quotas, &model.Quota{
+				Org:      rule.Team,
+				Granted:  results[i].Granted,
+				Required: results[i].Required,
+			})
+		}
+	}
+	status.Overrides = config.Overrides
+	approved := status.IsApproved()
+	err = remote.SetStatus(c, user, repo, issue.Number, status)
+	if err != nil {
+		log.Errorf("Error setting status for %s pr %d. %s", repo.Slug, issue.Number, err)
+		return nil, &hookError{500, fmt.Sprintf("Error setting status. %s.", err)}
+	}
+	for _, result := range results {
+		if len(result.Context) == 0 {
+			continue
+		}
+		err = remote.SetStatus(c, user, repo, issue.Number, result)
+		if err != nil {
+			log.Errorf("Error setting status %s for %s pr %d. %s", result.Context, repo.Slug, issue.Number, err)
+			return nil, &hookError{500, fmt.Sprintf("Error setting status %s. %s.", result.Context, err)}
+		}
 	}
 
-	log.Debugf("processed comment for %s. received %d of %d approvals", repo.Slug, len(approvers), config.Approvals)
+	log.Debugf("processed comment for %s. received %d of %d approvals", repo.Slug, status.Granted, status.Required)
 
-	c.IndentedJSON(200, gin.H{
+	return gin.H{
 		"approvers":   maintainer.People,
 		"settings":    config,
+		"author":      author,
 		"approved":    approved,
+		"wip":         status.WorkInProgress,
+		"window_ends": status.WindowEnds,
+		"granted":     status.Granted,
+		"required":    status.Required,
 		"approved_by": approvers,
+		"blocked_by":  blockers,
+		"vetoed_by":   vetoes,
+		"rules":       results,
+	}, nil
+}
+
+// getIssue is a helper function that fills in the details of the pull
+// request that are not included in the hook payload.
+func getIssue(c *gin.Context, user *model.User, repo *model.Repo, issue *model.Issue) *hookError {
+	pr, err := remote.GetIssue(c, user, repo, issue.Number)
+	if err != nil {
+		log.Errorf("Error retrieving pull request %s pr %d. %s", repo.Slug, issue.Number, err)
+		return &hookError{500, fmt.Sprintf("Error retrieving pull request. %s.", err)}
+	}
+	issue.Base = pr.Base
+	issue.Draft = pr.Draft
+	issue.Created = pr.Created
+	if len(issue.Branch) == 0 {
+		issue.Branch = pr.Branch
+	}
+	if len(issue.Title) == 0 {
+		issue.Title = pr.Title
+	}
+	if len(issue.Author) == 0 {
+		issue.Author = pr.Author
+	}
+	return nil
+}
+
+// getAuthor is a helper function that classifies the author of the pull
+// request. Allowlisted bots are recognized by login, and other authors
+// are only classified by the remote system when the approval policy
+// depends on the classification.
+func getAuthor(c *gin.Context, user *model.User, repo *model.Repo, config *model.Config, login string) (*model.Author, *hookError) {
+	if config.IsBot(login) || !config.ClassifiesAuthors() {
+		return &model.Author{Login: login}, nil
+	}
+	author, err := cache.GetAuthor(c, user, repo, login)
+	if err != nil {
+		log.Errorf("Error classifying author %s for %s. %s", login, repo.Slug, err)
+		return nil, &hookError{500, fmt.Sprintf("Error classifying author %s. %s.", login, err)}
+	}
+	return author, nil
+}
+
+// scheduleReview is a helper function that schedules the approval status
+// of the pull request to be evaluated again at the given time. The job is
+// stored, so that it is scheduled again when the server restarts.
+func scheduleReview(c *gin.Context, repo *model.Repo, num int, at time.Time) {
+	job := &model.Job{RepoID: repo.ID, Number: num, Time: at.Unix()}
+	err := store.CreateJob(c, job)
+	if err != nil {
+		log.Warnf("Error storing the review of %s pr %d. %s", repo.Slug, num, err)
+	}
+	scheduleJob(c, repo, job)
+}
+
+// scheduleJob is a helper function that schedules the stored job. The pull
+// request is fetched again, since it may have changed in the meantime.
+func scheduleJob(c *gin.Context, repo *model.Repo, job *model.Job) {
+	key := fmt.Sprintf("%s#%d", model.JoinRemote(repo.Remote, repo.Slug), job.Number)
+	copied := c.Copy()
+	scheduler.Schedule(c, key, time.Unix(job.Time, 0), func() {
+		err := store.DeleteJob(copied, job)
+		if err != nil {
+			log.Warnf("Error deleting the review of %s pr %d. %s", repo.Slug, job.Number, err)
+		}
+		// errors are logged by the evaluation, and are retried
+		// with the next comment or review.
+		evaluate(copied, repo, &model.Issue{Number: job.Number})
 	})
 }
 
+// Reschedule returns a middleware that schedules the stored jobs again
+// with the first request after the server starts, since the scheduler
+// keeps the jobs in memory. Jobs due while the server was down run
+// immediately.
+func Reschedule() gin.HandlerFunc {
+	var once sync.Once
+	return func(c *gin.Context) {
+		once.Do(func() {
+			rescheduleJobs(c)
+		})
+		c.Next()
+	}
+}
+
+// rescheduleJobs is a helper function that schedules the stored jobs,
+// deleting the jobs of repositories that are no longer active.
+func rescheduleJobs(c *gin.Context) {
+	jobs, err := store.GetJobList(c)
+	if err != nil {
+		log.Errorf("Error getting the scheduled reviews. %s", err)
+		return
+	}
+	for _, job := range jobs {
+		repo, err := store.GetRepo(c, job.RepoID)
+		if err != nil {
+			store.DeleteJob(c, job)
+			continue
+		}
+		scheduleJob(c, repo, job)
+	}
+}
+
+// getCommentsSince is a helper function that filters the list of
+// comments, dropping comments posted before the given time.
+func getCommentsSince(comments []*model.Comment, since time.Time) []*model.Comment {
+	filtered := []*model.Comment{}
+	for _, comment := range comments {
+		if comment.Created.Before(since) {
+			continue
+		}
+		filtered = append(filtered, comment)
+	}
+	return filtered
+}
+
+// getReviewsSince is a helper function that filters the list of
+// reviews, dropping reviews submitted before the given time.
+func getReviewsSince(reviews []*model.Review, since time.Time) []*model.Review {
+	filtered := []*model.Review{}
+	for _, review := range reviews {
+		if review.Created.Before(since) {
+			continue
+		}
+		filtered = append(filtered, review)
+	}
+	return filtered
+}
+
 // getApprovers is a helper function that analyzes the list of comments
-// and returns the list of approvers.
-func getApprovers(config *model.Config, maintainer *model.Maintainer, issue *model.Issue, comments []*model.Comment) []*model.Person {
-	approverm := map[string]bool{}
+// and reviews and returns the list of approvers, and the list of
+// maintainers blocking the pull request with a request for changes.
+func getApprovers(config *model.Config, maintainer *model.Maintainer, issue *model.Issue, comments []*model.Comment, reviews []*model.Review) ([]*model.Person, []*model.Person) {
+	approverm := map[string]time.Time{}
 	approvers := []*model.Person{}
+	blockers := []*model.Person{}
 
 	matcher, err := regexp.Compile(config.Pattern)
 	if err != nil {
 		// this should never happen
-		return approvers
+		return approvers, blockers
 	}
 
-	for _, comment := range comments {
+	// comments are sorted newest first, and evaluated in reverse
+	// to let the most recent approval or retraction from each
+	// maintainer decide their vote.
+	comments = sortComments(comments)
+	retractm := map[string]time.Time{}
+	for i := len(comments) - 1; i >= 0; i-- {
+		comment := comments[i]
+		// a veto or a lift is not an approval, even if it
+		// also matches the approval pattern.
+		if config.IsBlock(comment.Body) || config.IsLift(comment.Body) {
+			continue
+		}
 		// cannot lgtm your own pull request
 		if config.SelfApprovalOff && comment.Author == issue.Author {
 			continue
 		}
 		// the user must be a valid maintainer of the project
-		person, ok := maintainer.People[comment.Author]
-		if !ok {
+		if _, ok := maintainer.People[comment.Author]; !ok {
+			continue
+		}
+		// the retract pattern is checked first, since a retraction
+		// will often also match the approval pattern.
+		switch {
+		case config.IsRetract(comment.Body):
+			delete(approverm, comment.Author)
+			retractm[comment.Author] = comment.Created
+		case matcher.MatchString(comment.Body):
+			approverm[comment.Author] = comment.Created
+			delete(retractm, comment.Author)
+		}
+	}
+
+	// reviews are listed in chronological order, so the most
+	// recent review from each maintainer decides their vote.
+	reviewm := map[string]*model.Review{}
+	for _, review := range reviews {
+		// cannot approve your own pull request
+		if config.SelfApprovalOff && review.Author == issue.Author {
+			continue
+		}
+		// the user must be a valid maintainer of the project
+		if _, ok := maintainer.People[review.Author]; !ok {
 			continue
 		}
-		// the same author can't approve something twice
+		switch review.State {
+		case model.ReviewApproved, model.ReviewChangesRequested:
+			reviewm[review.Author] = review
+		case model.ReviewDismissed:
+			delete(reviewm, review.Author)
+		}
+	}
+
+	blockerm := map[string]bool{}
+	for login, review := range reviewm {
+		switch review.State {
+		case model.ReviewApproved:
+			// a later retraction takes back the approved review.
+			if created, ok := retractm[login]; ok && created.After(review.Created) {
+				continue
+			}
+			if _, ok := approverm[login]; !ok {
+				approverm[login] = review.Created
+			}
+		case model.ReviewChangesRequested:
+			// a request for changes blocks the pull request until
+			// the same maintainer approves it again.
+			if created, ok := approverm[login]; ok && created.After(review.Created) {
+				continue
+			}
+			delete(approverm, login)
+			blockerm[login] = true
+		}
+	}
+
+	// the lists are built in the order the votes were cast
+	// so that the results are stable.
+	for _, comment := range comments {
 		if _, ok := approverm[comment.Author]; ok {
+			approvers = append(approvers, maintainer.People[comment.Author])
+			delete(approverm, comment.Author)
+		}
+	}
+	for _, review := range reviews {
+		if _, ok := approverm[review.Author]; ok {
+			approvers = append(approvers, maintainer.People[review.Author])
+			delete(approverm, review.Author)
+		}
+		if blockerm[review.Author] {
+			blockers = append(blockers, maintainer.People[review.Author])
+			delete(blockerm, review.Author)
+		}
+	}
+
+	return approvers, blockers
+}
+
+// getVetoes is a helper function that analyzes the list of comments and
+// reviews and returns the list of maintainers vetoing the pull request with
+// a comment matching the block pattern. A veto is lifted when the same
+// maintainer later approves the pull request, or posts a lift comment.
+func getVetoes(config *model.Config, maintainer *model.Maintainer, comments []*model.Comment, reviews []*model.Review) []*model.Person {
+	vetoes := []*model.Person{}
+	if len(config.BlockPattern) == 0 {
+		return vetoes
+	}
+
+	// comments are sorted newest first, so the first veto, lift or
+	// approval from each maintainer decides whether the veto stands.
+	comments = sortComments(comments)
+	decided := map[string]bool{}
+	vetom := map[string]time.Time{}
+	for _, comment := range comments {
+		if _, ok := maintainer.People[comment.Author]; !ok {
 			continue
 		}
-		// verify the comment matches the approval pattern
-		if matcher.MatchString(comment.Body) {
-			approverm[comment.Author] = true
-			approvers = append(approvers, person)
+		if decided[comment.Author] {
+			continue
+		}
+		switch {
+		case config.IsBlock(comment.Body):
+			vetom[comment.Author] = comment.Created
+			decided[comment.Author] = true
+		case config.IsRetract(comment.Body):
+			// a retraction neither vetoes nor lifts a veto.
+		case config.IsLift(comment.Body), config.IsMatch(comment.Body):
+			decided[comment.Author] = true
+		}
+	}
+	for _, review := range reviews {
+		created, ok := vetom[review.Author]
+		if ok && review.State == model.ReviewApproved && review.Created.After(created) {
+			delete(vetom, review.Author)
+		}
+	}
+
+	for _, comment := range comments {
+		if _, ok := vetom[comment.Author]; ok {
+			vetoes = append(vetoes, maintainer.People[comment.Author])
+			delete(vetom, comment.Author)
+		}
+	}
+	return vetoes
+}
+
+// sortComments is a helper function that returns a copy of the comments
+// sorted newest first, since the remote systems do not agree on the order
+// comments are listed in.
+func sortComments(comments []*model.Comment) []*model.Comment {
+	sorted := make([]*model.Comment, len(comments))
+	copy(sorted, comments)
+	sort.Stable(commentsByNewest(sorted))
+	return sorted
+}
+
+// commentsByNewest sorts comments by creation time, newest first.
+type commentsByNewest []*model.Comment
+
+func (c commentsByNewest) Len() int           { return len(c) }
+func (c commentsByNewest) Swap(i, j int)      { c[i], c[j] = c[j], c[i] }
+func (c commentsByNewest) Less(i, j int) bool { return c[i].Created.After(c[j].Created) }
+
+// removePeople is a helper function that removes the people
+// from the list.
+func removePeople(list []*model.Person, people []*model.Person) []*model.Person {
+	removed := map[string]bool{}
+	for _, person := range people {
+		removed[person.Login] = true
+	}
+	filtered := []*model.Person{}
+	for _, person := range list {
+		if !removed[person.Login] {
+			filtered = append(filtered, person)
 		}
 	}
+	return filtered
+}
 
-	return approvers
+// getPending is a helper function that returns the sorted logins of
+// the maintainers eligible to approve the pull request that have
+// neither approved nor blocked it.
+func getPending(config *model.Config, maintainer *model.Maintainer, issue *model.Issue, approvers, blockers []*model.Person) []string {
+	responded := map[string]bool{}
+	for _, person := range approvers {
+		responded[person.Login] = true
+	}
+	for _, person := range blockers {
+		responded[person.Login] = true
+	}
+	if config.SelfApprovalOff {
+		responded[issue.Author] = true
+	}
+	var pending []string
+	for login := range maintainer.People {
+		if !responded[login] {
+			pending = append(pending, login)
+		}
+	}
+	sort.Strings(pending)
+	return pending
+}
+
+// setBranches is a helper function that protects the branches matching
+// the branch patterns in the .lgtm file. The applied patterns are cached,
+// so protection is applied again when the patterns change, or when the
+// cache expires to protect branches created in the meantime.
+func setBranches(c *gin.Context, user *model.User, repo *model.Repo, config *model.Config) {
+	patterns := strings.Join(config.BranchPatterns(), ",")
+	key := fmt.Sprintf("branches:%s", model.JoinRemote(repo.Remote, repo.Slug))
+	if val, err := cache.Get(c, key); err == nil && val.(string) == patterns {
+		return
+	}
+	err := remote.SetBranches(c, user, repo, config.BranchPatterns())
+	if err != nil {
+		log.Warnf("Error protecting branches %s for %s. %s", patterns, repo.Slug, err)
+		return
+	}
+	cache.Set(c, key, patterns)
+}
+
+// getCodeOwners is a helper function that builds the list of maintainers
+// from the CODEOWNERS file at the git reference, and adds a rule for each set of owners of the
+// changed files, requiring an approval from one of the owners.
+func getCodeOwners(c *gin.Context, user *model.User, repo *model.Repo, ref string, config *model.Config, files []string) (*model.Maintainer, error) {
+	_, file, err := remote.FindContents(c, user, repo, model.CodeOwnersPaths, ref)
+	if err != nil {
+		return nil, err
+	}
+	owners, err := model.ParseCodeOwners(file)
+	if err != nil {
+		return nil, err
+	}
+	members := map[string][]string{}
+	for _, team := range owners.Teams() {
+		teammates, err := cache.GetMembers(c, user, team)
+		if err != nil {
+			return nil, fmt.Errorf("Error getting members of team %s. %s", team, err)
+		}
+		for _, teammate := range teammates {
+			members[team] = append(members[team], teammate.Login)
+		}
+	}
+	config.Rules = append(config.Rules, owners.Rules(files)...)
+	// the rules of the code owners are subject to the server policy
+	// as well as the rules of the .lgtm file.
+	config.Enforce(policy.FromContext(c), repo.Owner)
+	return owners.Maintainer(members), nil
+}
+
+// getRule is a helper function that describes the approval rule.
+func getRule(config *model.Config, rule *model.Rule) string {
+	team := config.Team
+	if len(rule.Team) != 0 {
+		team = rule.Team
+	}
+	desc := fmt.Sprintf("%d approvals from %s matching %s", rule.Approvals, team, config.Pattern)
+	if len(config.Rules) == 0 {
+		return desc
+	}
+	return fmt.Sprintf("%s: %s", rule.Name, desc)
+}
+
+// appendPeople is a helper function that appends the people to the
+// list, skipping people already in the list.
+func appendPeople(list []*model.Person, people []*model.Person) []*model.Person {
+	for _, person := range people {
+		var found bool
+		for _, existing := range list {
+			if existing.Login == person.Login {
+				found = true
+				break
+			}
+		}
+		if !found {
+			list = append(list, person)
+		}
+	}
+	return list
+}
+
+// mergeStatus is a helper function that combines the status of each
+// matching rule into the overall status of the pull request, which is
+// approved only if every rule is satisfied.
+func mergeStatus(results []*model.Status) *model.Status {
+	if len(results) == 1 {
+		status := *results[0]
+		status.Context = ""
+		return &status
+	}
+	status := new(model.Status)
+	var rules []string
+	pending := map[string]bool{}
+	for _, result := range results {
+		// approvals beyond those required by a rule do not count
+		// towards the other rules.
+		granted := result.Granted
+		if granted > result.Required {
+			granted = result.Required
+		}
+		status.Granted += granted
+		status.Required += result.Required
+		status.Approvers = appendLogins(status.Approvers, result.Approvers)
+		status.Blockers = appendLogins(status.Blockers, result.Blockers)
+		status.Vetoes = appendLogins(status.Vetoes, result.Vetoes)
+		for _, login := range result.Pending {
+			pending[login] = true
+		}
+		rules = append(rules, result.Rule)
+		status.Link = result.Link
+	}
+	for login := range pending {
+		status.Pending = append(status.Pending, login)
+	}
+	sort.Strings(status.Pending)
+	status.Rule = strings.Join(rules, "; ")
+	return status
+}
+
+// appendLogins is a helper function that appends the logins to the
+// list, skipping logins already in the list.
+func appendLogins(list []string, logins []string) []string {
+	for _, login := range logins {
+		var found bool
+		for _, existing := range list {
+			if existing == login {
+				found = true
+				break
+			}
+		}
+		if !found {
+			list = append(list, login)
+		}
+	}
+	return list
+}
+
+// AppHook processes the installation events delivered to the application
+// webhook, which are not bound to a repository. The remote query parameter
+// names the remote the application belongs to.
+func AppHook(c *gin.Context) {
+	name := c.Query("remote")
+	if !remote.Use(c, name) {
+		log.Errorf("Error processing installation event. Remote %s is not configured.", name)
+		c.String(404, "Remote %s is not configured.", name)
+		return
+	}
+	err := remote.SetInstallation(c, c.Request)
+	if err != nil {
+		log.Errorf("Error processing installation event. %s", err)
+		c.String(400, "Error processing installation event. %s", err)
+		return
+	}
+	c.String(200, "pong")
+}
+
+// checkSignature is a helper function that verifies the HMAC signature
+// of the hook payload, computed by the remote with the repository secret.
+// GitLab does not sign payloads and instead sends the secret verbatim,
+// Gitea and Gogs send the signature without the algorithm prefix, and
+// Bitbucket Server sends a sha256 signature in the X-Hub-Signature header.
+func checkSignature(r *http.Request, payload []byte, secret string) bool {
+	var (
+		sig    string
+		prefix string
+		hasher func() hash.Hash
+	)
+	switch {
+	case r.Header.Get("X-Gitlab-Token") != "":
+		return hmac.Equal([]byte(r.Header.Get("X-Gitlab-Token")), []byte(secret))
+	case r.Header.Get("X-Hub-Signature-256") != "":
+		sig, prefix, hasher = r.Header.Get("X-Hub-Signature-256"), "sha256=", sha256.New
+	case strings.HasPrefix(r.Header.Get("X-Hub-Signature"), "sha256="):
+		sig, prefix, hasher = r.Header.Get("X-Hub-Signature"), "sha256=", sha256.New
+	case r.Header.Get("X-Hub-Signature") != "":
+		sig, prefix, hasher = r.Header.Get("X-Hub-Signature"), "sha1=", sha1.New
+	case r.Header.Get("X-Gitea-Signature") != "":
+		sig, prefix, hasher = "sha256="+r.Header.Get("X-Gitea-Signature"), "sha256=", sha256.New
+	case r.Header.Get("X-Gogs-Signature") != "":
+		sig, prefix, hasher = "sha256="+r.Header.Get("X-Gogs-Signature"), "sha256=", sha256.New
+	default:
+		return false
+	}
+	if !strings.HasPrefix(sig, prefix) {
+		return false
+	}
+	mac := hmac.New(hasher, []byte(secret))
+	mac.Write(payload)
+	want := prefix + hex.EncodeToString(mac.Sum(nil))
+	return hmac.Equal([]byte(sig), []byte(want))
 }
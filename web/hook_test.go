@@ -0,0 +1,593 @@
+package web
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/lgtmco/lgtm/cache"
+	"github.com/lgtmco/lgtm/model"
+	remotes "github.com/lgtmco/lgtm/remote"
+	"github.com/lgtmco/lgtm/scheduler"
+	"github.com/lgtmco/lgtm/shared/token"
+
+	remote "github.com/lgtmco/lgtm/remote/mock"
+	store "github.com/lgtmco/lgtm/store/mock"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/franela/goblin"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestHook(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logrus.SetOutput(ioutil.Discard)
+
+	g := goblin.Goblin(t)
+
+	g.Describe("Hook endpoint", func() {
+
+		var (
+			s *store.Store
+			r *remote.Remote
+			e *gin.Engine
+		)
+
+		g.BeforeEach(func() {
+			s = new(store.Store)
+			r = new(remote.Remote)
+			e = gin.New()
+			e.Use(func(c *gin.Context) {
+				c.Set("store", s)
+				c.Set("remote", r)
+				cache.ToContext(c, cache.Default())
+			})
+			e.POST("/hook", Hook)
+		})
+
+		g.It("Should reject a missing token", func() {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/hook", bytes.NewBufferString(fakePayload))
+			sign(req, fakePayload, fakeRepo.Secret)
+			e.ServeHTTP(w, req)
+
+			g.Assert(w.Code).Equal(401)
+			s.AssertNotCalled(t, "GetRepoSlug", mock.Anything, mock.Anything)
+			r.AssertNotCalled(t, "GetHook", mock.Anything)
+		})
+
+		g.It("Should reject a token of the wrong kind", func() {
+			sig, _ := token.New(token.UserToken, fakeRepo.Slug).Sign(fakeRepo.Secret)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/hook?access_token="+sig, bytes.NewBufferString(fakePayload))
+			sign(req, fakePayload, fakeRepo.Secret)
+			e.ServeHTTP(w, req)
+
+			g.Assert(w.Code).Equal(401)
+			s.AssertNotCalled(t, "GetRepoSlug", mock.Anything, mock.Anything)
+			r.AssertNotCalled(t, "GetHook", mock.Anything)
+		})
+
+		g.It("Should reject a token signed with the wrong secret", func() {
+			s.On("GetRepoSlug", "", fakeRepo.Slug).Return(fakeRepo, nil)
+			sig, _ := token.New(token.HookToken, fakeRepo.Slug).Sign("not-the-secret")
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/hook?access_token="+sig, bytes.NewBufferString(fakePayload))
+			sign(req, fakePayload, fakeRepo.Secret)
+			e.ServeHTTP(w, req)
+
+			g.Assert(w.Code).Equal(401)
+			r.AssertNotCalled(t, "GetHook", mock.Anything)
+		})
+
+		g.It("Should reject an invalid signature", func() {
+			s.On("GetRepoSlug", "", fakeRepo.Slug).Return(fakeRepo, nil)
+			sig, _ := token.New(token.HookToken, fakeRepo.Slug).Sign(fakeRepo.Secret)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/hook?access_token="+sig, bytes.NewBufferString(fakePayload))
+			sign(req, fakePayload, "not-the-secret")
+			e.ServeHTTP(w, req)
+
+			g.Assert(w.Code).Equal(401)
+			r.AssertNotCalled(t, "GetHook", mock.Anything)
+		})
+
+		g.It("Should reject a payload for another repository", func() {
+			s.On("GetRepoSlug", "", fakeRepo.Slug).Return(fakeRepo, nil)
+			r.On("GetHook", mock.Anything).Return(&model.Hook{
+				Repo:  &model.Repo{Slug: "octocat/Spoon-Knife"},
+				Issue: &model.Issue{Number: 1},
+			}, nil)
+			sig, _ := token.New(token.HookToken, fakeRepo.Slug).Sign(fakeRepo.Secret)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/hook?access_token="+sig, bytes.NewBufferString(fakePayload))
+			sign(req, fakePayload, fakeRepo.Secret)
+			e.ServeHTTP(w, req)
+
+			g.Assert(w.Code).Equal(401)
+			s.AssertNotCalled(t, "GetRepoSlug", "", "octocat/Spoon-Knife")
+			s.AssertNotCalled(t, "GetUser", mock.Anything)
+		})
+
+		g.It("Should accept a signed delivery", func() {
+			s.On("GetRepoSlug", "", fakeRepo.Slug).Return(fakeRepo, nil)
+			r.On("GetHook", mock.Anything).Return(nil, nil)
+			sig, _ := token.New(token.HookToken, fakeRepo.Slug).Sign(fakeRepo.Secret)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/hook?access_token="+sig, bytes.NewBufferString(fakePayload))
+			sign(req, fakePayload, fakeRepo.Secret)
+			e.ServeHTTP(w, req)
+
+			g.Assert(w.Code).Equal(200)
+			g.Assert(w.Body.String()).Equal("pong")
+		})
+
+		g.It("Should reject an unsigned delivery", func() {
+			s.On("GetRepoSlug", "", fakeRepo.Slug).Return(fakeRepo, nil)
+			sig, _ := token.New(token.HookToken, fakeRepo.Slug).Sign(fakeRepo.Secret)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/hook?access_token="+sig, bytes.NewBufferString(fakePayload))
+			e.ServeHTTP(w, req)
+
+			g.Assert(w.Code).Equal(401)
+			r.AssertNotCalled(t, "SetHook", mock.Anything, mock.Anything, mock.Anything)
+			r.AssertNotCalled(t, "GetHook", mock.Anything)
+		})
+
+		g.It("Should reject an unsigned delivery for a repository activated before signing", func() {
+			legacy := *fakeRepo
+			legacy.Signed = false
+			sig, _ := token.New(token.HookToken, legacy.Slug).Sign(legacy.Secret)
+			s.On("GetRepoSlug", "", legacy.Slug).Return(&legacy, nil)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/hook?access_token="+sig, bytes.NewBufferString(fakePayload))
+			e.ServeHTTP(w, req)
+
+			g.Assert(w.Code).Equal(401)
+			r.AssertNotCalled(t, "SetHook", mock.Anything, mock.Anything, mock.Anything)
+			r.AssertNotCalled(t, "GetHook", mock.Anything)
+		})
+
+		g.It("Should veto every rule from any maintainer", func() {
+			sig, _ := token.New(token.HookToken, fakeRepo.Slug).Sign(fakeRepo.Secret)
+			user := &model.User{ID: 1, Login: "octocat"}
+			hook := &model.Hook{
+				Repo:  fakeRepo,
+				Issue: &model.Issue{Number: 1, Base: "6dcb09b5"},
+			}
+			s.On("GetRepoSlug", "", fakeRepo.Slug).Return(fakeRepo, nil)
+			r.On("GetHook", mock.Anything).Return(hook, nil)
+			r.On("GetAppUser", fakeRepo).Return(user, nil)
+			r.On("GetContentsRef", user, fakeRepo, ".lgtm", "6dcb09b5").Return([]byte(fakeConfigRules), nil)
+			r.On("GetContentsRef", user, fakeRepo, "MAINTAINERS", "6dcb09b5").Return([]byte(fakeMaintainerOrgs), nil)
+			r.On("GetContentsRef", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil, fmt.Errorf("Not Found"))
+			r.On("GetFiles", user, fakeRepo, 1).Return([]string{"docs/index.md"}, nil)
+			r.On("GetComments", user, fakeRepo, 1).Return([]*model.Comment{
+				{Author: "bradrydzewski", Body: "NOT LGTM", Created: time.Now()},
+				{Author: "janedoe", Body: "LGTM", Created: time.Now()},
+			}, nil)
+			r.On("GetReviews", user, fakeRepo, 1).Return([]*model.Review{}, nil)
+			r.On("SetStatus", user, fakeRepo, 1, mock.Anything).Return(nil)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/hook?access_token="+sig, bytes.NewBufferString(fakePayload))
+			sign(req, fakePayload, fakeRepo.Secret)
+			e.ServeHTTP(w, req)
+			g.Assert(w.Code).Equal(200)
+
+			out := struct {
+				Rules []*model.Status `json:"rules"`
+			}{}
+			json.Unmarshal(w.Body.Bytes(), &out)
+			g.Assert(len(out.Rules)).Equal(1)
+			g.Assert(out.Rules[0].Approvers).Equal([]string{"janedoe"})
+			g.Assert(out.Rules[0].Vetoes).Equal([]string{"bradrydzewski"})
+			g.Assert(out.Rules[0].IsApproved()).IsFalse()
+		})
+
+		g.It("Should accept a delivery with the GitLab token", func() {
+			s.On("GetRepoSlug", "", fakeRepo.Slug).Return(fakeRepo, nil)
+			r.On("GetHook", mock.Anything).Return(nil, nil)
+			sig, _ := token.New(token.HookToken, fakeRepo.Slug).Sign(fakeRepo.Secret)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/hook?access_token="+sig, bytes.NewBufferString(fakePayload))
+			req.Header.Set("X-Gitlab-Token", fakeRepo.Secret)
+			e.ServeHTTP(w, req)
+
+			g.Assert(w.Code).Equal(200)
+		})
+
+		g.It("Should route the hook to the repository remote", func() {
+			enterprise := new(remote.Remote)
+			e = gin.New()
+			e.Use(func(c *gin.Context) {
+				c.Set("store", s)
+				c.Set("remotes", remotes.Remotes{"": r, "enterprise": enterprise})
+				c.Set("remote", r)
+			})
+			e.POST("/hook", Hook)
+
+			repo := *fakeRepo
+			repo.Remote = "enterprise"
+			s.On("GetRepoSlug", "enterprise", repo.Slug).Return(&repo, nil)
+			enterprise.On("GetHook", mock.Anything).Return(nil, nil)
+			sig, _ := token.New(token.HookToken, "enterprise:"+repo.Slug).Sign(repo.Secret)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/hook?access_token="+sig, bytes.NewBufferString(fakePayload))
+			sign(req, fakePayload, repo.Secret)
+			e.ServeHTTP(w, req)
+
+			g.Assert(w.Code).Equal(200)
+			enterprise.AssertCalled(t, "GetHook", mock.Anything)
+			r.AssertNotCalled(t, "GetHook", mock.Anything)
+		})
+
+		g.It("Should accept a delivery with a sha256 signature", func() {
+			s.On("GetRepoSlug", "", fakeRepo.Slug).Return(fakeRepo, nil)
+			r.On("GetHook", mock.Anything).Return(nil, nil)
+			sig, _ := token.New(token.HookToken, fakeRepo.Slug).Sign(fakeRepo.Secret)
+
+			mac := hmac.New(sha256.New, []byte(fakeRepo.Secret))
+			mac.Write([]byte(fakePayload))
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/hook?access_token="+sig, bytes.NewBufferString(fakePayload))
+			req.Header.Set("X-Hub-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+			e.ServeHTTP(w, req)
+
+			g.Assert(w.Code).Equal(200)
+		})
+	})
+}
+
+func TestReschedule(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logrus.SetOutput(ioutil.Discard)
+
+	g := goblin.Goblin(t)
+
+	g.Describe("Scheduled reviews", func() {
+
+		g.It("Should schedule the stored reviews again", func() {
+			s := new(store.Store)
+			sched := &fakeScheduler{}
+			now := time.Now()
+			active := &model.Job{RepoID: fakeRepo.ID, Number: 1, Time: now.Unix()}
+			inactive := &model.Job{RepoID: 2, Number: 1, Time: now.Unix()}
+			s.On("GetJobList").Return([]*model.Job{active, inactive}, nil).Once()
+			s.On("GetRepo", fakeRepo.ID).Return(fakeRepo, nil)
+			s.On("GetRepo", int64(2)).Return(nil, fmt.Errorf("Not Found"))
+			s.On("DeleteJob", inactive).Return(nil)
+
+			e := gin.New()
+			e.Use(func(c *gin.Context) {
+				c.Set("store", s)
+				scheduler.ToContext(c, sched)
+			})
+			e.Use(Reschedule())
+			e.GET("/", func(c *gin.Context) {
+				c.String(200, "")
+			})
+			for i := 0; i < 2; i++ {
+				w := httptest.NewRecorder()
+				req, _ := http.NewRequest("GET", "/", nil)
+				e.ServeHTTP(w, req)
+			}
+
+			g.Assert(sched.keys).Equal([]string{"octocat/Hello-World#1"})
+			g.Assert(sched.times[0].Unix()).Equal(now.Unix())
+			s.AssertCalled(t, "DeleteJob", inactive)
+			s.AssertNumberOfCalls(t, "GetJobList", 1)
+		})
+	})
+}
+
+// fakeScheduler records the scheduled jobs without running them.
+type fakeScheduler struct {
+	keys  []string
+	times []time.Time
+}
+
+func (s *fakeScheduler) Schedule(key string, at time.Time, job func()) {
+	s.keys = append(s.keys, key)
+	s.times = append(s.times, at)
+}
+
+func TestCommentsSince(t *testing.T) {
+	g := goblin.Goblin(t)
+
+	g.Describe("Comments since push", func() {
+		g.It("Should drop comments posted before the push", func() {
+			push := time.Date(2016, time.May, 1, 12, 0, 0, 0, time.UTC)
+			comments := []*model.Comment{
+				{Author: "bradrydzewski", Body: "LGTM", Created: push.Add(time.Hour)},
+				{Author: "mattnorris", Body: "LGTM", Created: push.Add(-time.Hour)},
+			}
+			got := getCommentsSince(comments, push)
+			g.Assert(len(got)).Equal(1)
+			g.Assert(got[0].Author).Equal("bradrydzewski")
+		})
+	})
+}
+
+func TestApprovers(t *testing.T) {
+	g := goblin.Goblin(t)
+
+	g.Describe("Approvers", func() {
+
+		var (
+			config     *model.Config
+			maintainer *model.Maintainer
+			issue      *model.Issue
+			now        = time.Date(2016, time.May, 1, 12, 0, 0, 0, time.UTC)
+		)
+
+		g.BeforeEach(func() {
+			config, _ = model.ParseConfigStr("")
+			maintainer, _ = model.ParseMaintainerStr("bradrydzewski\nmattnorris\noctocat\n")
+			issue = &model.Issue{Number: 1, Author: "octocat"}
+		})
+
+		g.It("Should count comment approvals once", func() {
+			comments := []*model.Comment{
+				{Author: "bradrydzewski", Body: "LGTM", Created: now.Add(time.Hour)},
+				{Author: "bradrydzewski", Body: "LGTM", Created: now},
+				{Author: "janedoe", Body: "LGTM", Created: now},
+			}
+			approvers, blockers := getApprovers(config, maintainer, issue, comments, nil)
+			g.Assert(len(approvers)).Equal(1)
+			g.Assert(len(blockers)).Equal(0)
+		})
+
+		g.It("Should count approved reviews", func() {
+			comments := []*model.Comment{
+				{Author: "bradrydzewski", Body: "LGTM", Created: now},
+			}
+			reviews := []*model.Review{
+				{Author: "bradrydzewski", State: model.ReviewApproved, Created: now},
+				{Author: "mattnorris", State: model.ReviewApproved, Created: now},
+				{Author: "janedoe", State: model.ReviewApproved, Created: now},
+			}
+			approvers, blockers := getApprovers(config, maintainer, issue, comments, reviews)
+			g.Assert(len(approvers)).Equal(2)
+			g.Assert(approvers[0].Login).Equal("bradrydzewski")
+			g.Assert(approvers[1].Login).Equal("mattnorris")
+			g.Assert(len(blockers)).Equal(0)
+		})
+
+		g.It("Should block on requested changes", func() {
+			comments := []*model.Comment{
+				{Author: "bradrydzewski", Body: "LGTM", Created: now},
+			}
+			reviews := []*model.Review{
+				{Author: "bradrydzewski", State: model.ReviewChangesRequested, Created: now.Add(time.Hour)},
+			}
+			approvers, blockers := getApprovers(config, maintainer, issue, comments, reviews)
+			g.Assert(len(approvers)).Equal(0)
+			g.Assert(len(blockers)).Equal(1)
+			g.Assert(blockers[0].Login).Equal("bradrydzewski")
+		})
+
+		g.It("Should unblock when the reviewer approves", func() {
+			reviews := []*model.Review{
+				{Author: "bradrydzewski", State: model.ReviewChangesRequested, Created: now},
+				{Author: "bradrydzewski", State: model.ReviewCommented, Created: now.Add(time.Minute)},
+				{Author: "bradrydzewski", State: model.ReviewApproved, Created: now.Add(time.Hour)},
+			}
+			approvers, blockers := getApprovers(config, maintainer, issue, nil, reviews)
+			g.Assert(len(approvers)).Equal(1)
+			g.Assert(len(blockers)).Equal(0)
+
+			comments := []*model.Comment{
+				{Author: "mattnorris", Body: "LGTM", Created: now.Add(time.Hour)},
+			}
+			reviews = []*model.Review{
+				{Author: "mattnorris", State: model.ReviewChangesRequested, Created: now},
+			}
+			approvers, blockers = getApprovers(config, maintainer, issue, comments, reviews)
+			g.Assert(len(approvers)).Equal(1)
+			g.Assert(len(blockers)).Equal(0)
+		})
+
+		g.It("Should ignore self approval reviews", func() {
+			config.SelfApprovalOff = true
+			reviews := []*model.Review{
+				{Author: "octocat", State: model.ReviewApproved, Created: now},
+			}
+			approvers, _ := getApprovers(config, maintainer, issue, nil, reviews)
+			g.Assert(len(approvers)).Equal(0)
+		})
+
+		g.It("Should retract an approval", func() {
+			comments := []*model.Comment{
+				{Author: "mattnorris", Body: "LGTM", Created: now.Add(2 * time.Hour)},
+				{Author: "mattnorris", Body: "un-lgtm", Created: now.Add(time.Hour)},
+				{Author: "bradrydzewski", Body: "/lgtm cancel", Created: now.Add(time.Hour)},
+				{Author: "bradrydzewski", Body: "LGTM", Created: now},
+				{Author: "mattnorris", Body: "LGTM", Created: now},
+			}
+			approvers, _ := getApprovers(config, maintainer, issue, comments, nil)
+			g.Assert(len(approvers)).Equal(1)
+			g.Assert(approvers[0].Login).Equal("mattnorris")
+
+			comments = []*model.Comment{
+				{Author: "mattnorris", Body: "unlgtm", Created: now.Add(time.Hour)},
+			}
+			reviews := []*model.Review{
+				{Author: "mattnorris", State: model.ReviewApproved, Created: now},
+			}
+			approvers, _ = getApprovers(config, maintainer, issue, comments, reviews)
+			g.Assert(len(approvers)).Equal(0)
+		})
+
+		g.It("Should retract an approval of comments listed oldest first", func() {
+			comments := []*model.Comment{
+				{Author: "mattnorris", Body: "LGTM", Created: now},
+				{Author: "mattnorris", Body: "un-lgtm", Created: now.Add(time.Hour)},
+				{Author: "mattnorris", Body: "LGTM", Created: now.Add(2 * time.Hour)},
+				{Author: "bradrydzewski", Body: "LGTM", Created: now},
+				{Author: "bradrydzewski", Body: "/lgtm cancel", Created: now.Add(time.Hour)},
+			}
+			approvers, _ := getApprovers(config, maintainer, issue, comments, nil)
+			g.Assert(len(approvers)).Equal(1)
+			g.Assert(approvers[0].Login).Equal("mattnorris")
+		})
+
+		g.It("Should list the maintainers pending a response", func() {
+			approvers := []*model.Person{{Login: "mattnorris"}}
+			pending := getPending(config, maintainer, issue, approvers, nil)
+			g.Assert(pending).Equal([]string{"bradrydzewski", "octocat"})
+
+			config.SelfApprovalOff = true
+			pending = getPending(config, maintainer, issue, approvers, nil)
+			g.Assert(pending).Equal([]string{"bradrydzewski"})
+		})
+
+		g.It("Should veto on a block comment", func() {
+			config, _ = model.ParseConfigStr(`block_pattern = "(?i)^NOT LGTM"`)
+			comments := []*model.Comment{
+				{Author: "mattnorris", Body: "LGTM", Created: now.Add(time.Hour)},
+				{Author: "bradrydzewski", Body: "NOT LGTM", Created: now},
+				{Author: "bradrydzewski", Body: "LGTM", Created: now.Add(-time.Hour)},
+			}
+			vetoes := getVetoes(config, maintainer, comments, nil)
+			g.Assert(len(vetoes)).Equal(1)
+			g.Assert(vetoes[0].Login).Equal("bradrydzewski")
+
+			approvers, _ := getApprovers(config, maintainer, issue, comments, nil)
+			approvers = removePeople(approvers, vetoes)
+			g.Assert(len(approvers)).Equal(1)
+			g.Assert(approvers[0].Login).Equal("mattnorris")
+		})
+
+		g.It("Should lift a veto", func() {
+			config, _ = model.ParseConfigStr(`
+block_pattern = "(?i)^NOT LGTM"
+lift_pattern = "(?i)^UNBLOCK"
+`)
+			comments := []*model.Comment{
+				{Author: "mattnorris", Body: "unblock", Created: now.Add(time.Hour)},
+				{Author: "mattnorris", Body: "NOT LGTM", Created: now},
+				{Author: "bradrydzewski", Body: "NOT LGTM", Created: now},
+				{Author: "janedoe", Body: "NOT LGTM", Created: now},
+			}
+			reviews := []*model.Review{
+				{Author: "bradrydzewski", State: model.ReviewApproved, Created: now.Add(time.Hour)},
+			}
+			vetoes := getVetoes(config, maintainer, comments, reviews)
+			g.Assert(len(vetoes)).Equal(0)
+
+			approvers, _ := getApprovers(config, maintainer, issue, comments, reviews)
+			g.Assert(len(approvers)).Equal(1)
+			g.Assert(approvers[0].Login).Equal("bradrydzewski")
+		})
+
+		g.It("Should veto and lift with comments listed oldest first", func() {
+			config, _ = model.ParseConfigStr(`
+block_pattern = "(?i)^NOT LGTM"
+lift_pattern = "(?i)^UNBLOCK"
+`)
+			comments := []*model.Comment{
+				{Author: "mattnorris", Body: "NOT LGTM", Created: now},
+				{Author: "bradrydzewski", Body: "unblock", Created: now},
+				{Author: "mattnorris", Body: "unblock", Created: now.Add(time.Hour)},
+				{Author: "bradrydzewski", Body: "NOT LGTM", Created: now.Add(time.Hour)},
+			}
+			vetoes := getVetoes(config, maintainer, comments, nil)
+			g.Assert(len(vetoes)).Equal(1)
+			g.Assert(vetoes[0].Login).Equal("bradrydzewski")
+		})
+
+		g.It("Should not veto without a block pattern", func() {
+			comments := []*model.Comment{
+				{Author: "bradrydzewski", Body: "NOT LGTM", Created: now},
+			}
+			g.Assert(len(getVetoes(config, maintainer, comments, nil))).Equal(0)
+		})
+
+		g.It("Should require every matching rule", func() {
+			merged := mergeStatus([]*model.Status{
+				{Granted: 3, Required: 2, Approvers: []string{"bradrydzewski", "mattnorris", "octocat"}},
+				{Granted: 0, Required: 1, Context: "approvals/docs", Pending: []string{"janedoe"}},
+			})
+			g.Assert(merged.Granted).Equal(2)
+			g.Assert(merged.Required).Equal(3)
+			g.Assert(merged.IsApproved()).IsFalse()
+			g.Assert(merged.Context).Equal("")
+			g.Assert(merged.Pending).Equal([]string{"janedoe"})
+
+			merged = mergeStatus([]*model.Status{
+				{Granted: 1, Required: 1, Context: "approvals/docs"},
+			})
+			g.Assert(merged.IsApproved()).IsTrue()
+			g.Assert(merged.Context).Equal("")
+		})
+	})
+}
+
+// sign is a helper function that signs the payload the same
+// way GitHub signs webhook deliveries.
+func sign(r *http.Request, payload, secret string) {
+	mac := hmac.New(sha1.New, []byte(secret))
+	mac.Write([]byte(payload))
+	r.Header.Set("X-Hub-Signature", fmt.Sprintf("sha1=%s", hex.EncodeToString(mac.Sum(nil))))
+}
+
+var (
+	fakeRepo = &model.Repo{
+		ID:     1,
+		UserID: 1,
+		Owner:  "octocat",
+		Name:   "Hello-World",
+		Slug:   "octocat/Hello-World",
+		Secret: "b9015b0857e16ac4d94a0ffd9a0b79c8",
+		Signed: true,
+	}
+	fakePayload = `{"zen":"Keep it logically awesome."}`
+
+	fakeConfigRules = `
+block_pattern = "NOT LGTM"
+
+[[rule]]
+name = "docs"
+paths = ["docs/**"]
+approvals = 1
+team = "docs"
+`
+	fakeMaintainerOrgs = `
+[people]
+
+	[people.bradrydzewski]
+	login = "bradrydzewski"
+
+	[people.janedoe]
+	login = "janedoe"
+
+[org]
+
+	[org.core]
+	people = ["bradrydzewski"]
+
+	[org.docs]
+	people = ["janedoe"]
+`
+)
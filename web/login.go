@@ -2,6 +2,7 @@ package web
 
 import (
 	"net/http"
+	"sort"
 	"time"
 
 	"github.com/lgtmco/lgtm/model"
@@ -25,6 +26,30 @@ func Login(c *gin.Context) {
 		return
 	}
 
+	// when multiple remotes are configured the user picks the remote
+	// to login with. The choice is stored in a cookie so that it is
+	// available when the remote redirects back after the oauth dance.
+	name, picked := c.Request.URL.Query()["remote"]
+	switch {
+	case picked:
+		httputil.SetCookie(c.Writer, c.Request, "user_remote", name[0])
+	case len(c.Query("code")) != 0:
+		if cookie, err := c.Request.Cookie("user_remote"); err == nil {
+			name = []string{cookie.Value}
+		}
+	case len(remote.RemotesFromContext(c)) > 1:
+		c.HTML(200, "login.html", gin.H{"remotes": remoteNames(c)})
+		return
+	}
+	if len(name) == 0 {
+		name = []string{""}
+	}
+	if !remote.Use(c, name[0]) {
+		log.Errorf("cannot authenticate user. Remote %s is not configured.", name[0])
+		c.Redirect(303, "/login?error=unknown_remote")
+		return
+	}
+
 	// when dealing with redirects we may need
 	// to adjust the content type. I cannot, however,
 	// rememver why, so need to revisit this line.
@@ -43,7 +68,7 @@ func Login(c *gin.Context) {
 	}
 
 	// get the user from the database
-	u, err := store.GetUserLogin(c, tmpuser.Login)
+	u, err := store.GetUserLogin(c, name[0], tmpuser.Login)
 	if err != nil {
 
 		// create the user account
@@ -52,6 +77,7 @@ func Login(c *gin.Context) {
 		u.Token = tmpuser.Token
 		u.Avatar = tmpuser.Avatar
 		u.Secret = model.Rand()
+		u.Remote = name[0]
 
 		// insert the user into the database
 		if err := store.CreateUser(c, u); err != nil {
@@ -73,7 +99,7 @@ func Login(c *gin.Context) {
 	}
 
 	exp := time.Now().Add(time.Hour * 72).Unix()
-	token := token.New(token.SessToken, u.Login)
+	token := token.New(token.SessToken, model.JoinRemote(u.Remote, u.Login))
 	tokenstr, err := token.SignExpires(u.Secret, exp)
 	if err != nil {
 		log.Errorf("cannot create token for %s. %s", u.Login, err)
@@ -86,21 +112,27 @@ func Login(c *gin.Context) {
 }
 
 // LoginToken authenticates a user with their GitHub token and
-// returns an LGTM API token in the response.
+// returns an LGTM API token in the response. The remote query
+// parameter names the remote the token belongs to.
 func LoginToken(c *gin.Context) {
+	name := c.Query("remote")
+	if !remote.Use(c, name) {
+		c.String(404, "Unable to authenticate user. Remote %s is not configured.", name)
+		return
+	}
 	access := c.Query("access_token")
 	login, err := remote.GetUserToken(c, access)
 	if err != nil {
 		c.String(403, "Unable to authenticate user. %s", err)
 		return
 	}
-	user, err := store.GetUserLogin(c, login)
+	user, err := store.GetUserLogin(c, name, login)
 	if err != nil {
 		c.String(404, "Unable to authenticate user %s. Not registered.", user.Login)
 		return
 	}
 	exp := time.Now().Add(time.Hour * 72).Unix()
-	token := token.New(token.UserToken, user.Login)
+	token := token.New(token.UserToken, model.JoinRemote(user.Remote, user.Login))
 	tokenstr, err := token.SignExpires(user.Secret, exp)
 	if err != nil {
 		c.AbortWithError(http.StatusInternalServerError, err)
@@ -118,6 +150,17 @@ type tokenPayload struct {
 	Expires int64  `json:"expires_in,omitempty"`
 }
 
+// remoteNames is a helper function that returns the sorted names
+// of the configured remotes, with the default remote listed first.
+func remoteNames(c *gin.Context) []string {
+	var names []string
+	for name := range remote.RemotesFromContext(c) {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 // Logout terminates the session for the currently authenticated user,
 // deleting all session cookies, and redirecting back to the main page.
 func Logout(c *gin.Context) {